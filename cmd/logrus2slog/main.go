@@ -0,0 +1,154 @@
+// Command logrus2slog rewrites Go source files that use logrus to use the logger package of go-kit
+// instead, automating most of the migration steps described in the documentation of logger.Slog:
+//
+//   - logrus.New() becomes logger.NewSlog(slog.Default());
+//   - logrus.Fields{...} literals become logger.SlogFields(...) calls;
+//   - *logrus.Entry, *logrus.Logger, logrus.FieldLogger, logrus.Ext1FieldLogger and logrus.StdLogger
+//     types become the matching logger interfaces;
+//   - the package-level functions, such as logrus.WithField or logrus.Infof, become their logger
+//     counterparts.
+//
+// The imports are updated accordingly. The references to logrus that cannot be converted,
+// such as logrus.SetLevel or logrus.InfoLevel, are reported on stderr as file:line:column: message,
+// and the logrus import is kept for them. So are the uses of the converted values that the logger
+// types do not support, such as l.SetOutput(w) for a *logrus.Logger, e.Data for a *logrus.Entry
+// or f["k"] for a logrus.Fields: these values are left unconverted, along with the values they
+// flow into or from in the file (through variables, struct fields, arguments and results of
+// the functions of the file), so that the rewritten code still compiles. WithFields calls
+// taking fields that are not converted, such as a logrus.Fields variable, are kept as well.
+//
+// Usage:
+//
+//	logrus2slog [-w] path ...
+//
+// The paths are files or directories, which are walked recursively (skipping vendor, testdata
+// and hidden directories). Without -w, the rewritten files are printed to stdout.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	write := flag.Bool("w", false, "write the result to the source files instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: logrus2slog [-w] path ...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	out := printTo(os.Stdout)
+	if *write {
+		out = writeFile
+	}
+	failed := false
+	for _, path := range flag.Args() {
+		if err := run(path, out, os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// output receives the rewritten content of the file name.
+type output func(name string, content []byte) error
+
+// printTo returns an output writing the rewritten files to w.
+func printTo(w io.Writer) output {
+	return func(_ string, content []byte) error {
+		_, err := w.Write(content)
+		return err
+	}
+}
+
+// writeFile is an output writing the rewritten files back.
+func writeFile(name string, content []byte) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, content, info.Mode().Perm())
+}
+
+// run rewrites the Go files at path, passing them to out, and writes the report to stderr.
+func run(path string, out output, stderr io.Writer) error {
+	return filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			base := d.Name()
+			if name != path && (base == "vendor" || base == "testdata" || strings.HasPrefix(base, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		return processFile(name, out, stderr)
+	})
+}
+
+func processFile(name string, out output, stderr io.Writer) error {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	content, issues, err := rewriteSource(name, src)
+	if err != nil {
+		return err
+	}
+	for _, i := range issues {
+		fmt.Fprintln(stderr, i)
+	}
+	if content == nil {
+		return nil
+	}
+	return out(name, content)
+}
+
+// rewriteSource rewrites src, the content of the file name. It returns nil if there is nothing
+// to rewrite, along with the references to logrus that could not be converted.
+func rewriteSource(name string, src []byte) ([]byte, []issue, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	changed, issues, newGroups := rewriteFile(fset, file)
+	if !changed {
+		return nil, issues, nil
+	}
+
+	var b bytes.Buffer
+	if err := format.Node(&b, fset, file); err != nil {
+		return nil, nil, fmt.Errorf("%s: failed to format the rewritten file: %w", name, err)
+	}
+	// format again, so that the added imports are sorted within their groups
+	out, err := format.Source(separateImports(b.Bytes(), newGroups))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: failed to format the rewritten file: %w", name, err)
+	}
+	return out, issues, nil
+}
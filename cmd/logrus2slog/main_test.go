@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const testSource = `package app
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	log *logrus.Entry
+}
+
+func NewService(l logrus.FieldLogger) *Service {
+	return &Service{log: l.WithField("svc", "x")}
+}
+
+func run(ctx context.Context) *logrus.Logger {
+	l := logrus.New()
+	l.WithFields(logrus.Fields{
+		"b": 2,
+		"a": 1, // one
+	}).Info("hello")
+	logrus.SetLevel(logrus.DebugLevel)
+	logrus.Infoln("x")
+	logrus.WithError(nil).Errorf("failed %d", 1)
+	return l
+}
+`
+
+const testResult = `package app
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	log logger.TraceFieldLogger[[]any, *logger.Slog]
+}
+
+func NewService(l logger.FieldLogger[[]any, *logger.Slog]) *Service {
+	return &Service{log: l.WithField("svc", "x")}
+}
+
+func run(ctx context.Context) logger.TraceFieldLogger[[]any, *logger.Slog] {
+	l := logger.NewSlog(slog.Default())
+	l.WithFields(logger.SlogFields(
+		"b", 2,
+		"a", 1, // one
+	)).Info("hello")
+	logrus.SetLevel(logrus.DebugLevel)
+	logrus.Infoln("x")
+	logger.WithError(nil).Errorf("failed %d", 1)
+	return l
+}
+`
+
+func TestRewriteSource(t *testing.T) {
+	c := qt.New(t)
+
+	out, issues, err := rewriteSource("a.go", []byte(testSource))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(out), qt.Equals, testResult)
+
+	var report []string
+	for _, i := range issues {
+		report = append(report, i.String())
+	}
+	c.Assert(report, qt.DeepEquals, []string{
+		"a.go:23:2: logrus.SetLevel is not converted",
+		"a.go:23:18: logrus.DebugLevel is not converted",
+		"a.go:24:2: logrus.Infoln has no counterpart; use logger.Info",
+	})
+}
+
+func TestRewriteSource_Imports(t *testing.T) {
+	c := qt.New(t)
+
+	out, issues, err := rewriteSource("b.go", []byte(`package logger
+
+import log "github.com/sirupsen/logrus"
+
+func Log(fields log.Fields) {
+	log.WithFields(fields).Info("x")
+}
+
+var std log.StdLogger = log.New()
+`))
+	c.Assert(err, qt.IsNil)
+	c.Assert(issues, qt.HasLen, 2)
+	c.Assert(issues[0].String(), qt.Equals, "b.go:5:17: logrus.Fields is only converted in composite literals; use logger.Fields with logger.FieldsSlog")
+	c.Assert(issues[1].String(), qt.Equals, "b.go:6:2: log.WithFields(fields) passes fields that are not converted to *logger.Slog; log.WithFields(...) is not converted")
+	c.Assert(string(out), qt.Equals, `package logger
+
+import (
+	"log/slog"
+
+	kitlogger "github.com/go-extras/go-kit/logger"
+	log "github.com/sirupsen/logrus"
+)
+
+func Log(fields log.Fields) {
+	log.WithFields(fields).Info("x")
+}
+
+var std kitlogger.BasicLogger = kitlogger.NewSlog(slog.Default())
+`)
+
+	out, issues, err = rewriteSource("c.go", []byte("package app\n\nimport \"github.com/sirupsen/logrus\"\n\nfunc f() { logrus.Info(\"x\") }\n"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(issues, qt.HasLen, 0)
+	c.Assert(string(out), qt.Equals, "package app\n\nimport \"github.com/go-extras/go-kit/logger\"\n\nfunc f() { logger.Info(\"x\") }\n")
+
+	out, issues, err = rewriteSource("e.go", []byte("package app\n\nimport \"github.com/sirupsen/logrus\"\n\nvar l = logrus.New()\n"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(issues, qt.HasLen, 0)
+	c.Assert(string(out), qt.Equals, "package app\n\nimport (\n\t\"log/slog\"\n\n\t\"github.com/go-extras/go-kit/logger\"\n)\n\nvar l = logger.NewSlog(slog.Default())\n")
+
+	out, issues, err = rewriteSource("d.go", []byte("package app\n\nimport \"fmt\"\n\nfunc f() { fmt.Println() }\n"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(issues, qt.HasLen, 0)
+	c.Assert(out, qt.IsNil)
+}
+
+func TestRewriteSource_UnsupportedUses(t *testing.T) {
+	c := qt.New(t)
+
+	out, issues, err := rewriteSource("u.go", []byte(`package app
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	log *logrus.Entry
+}
+
+func (s *Service) data() logrus.Fields {
+	return s.log.Data
+}
+
+func setup(w io.Writer, l *logrus.Logger, fl logrus.FieldLogger) {
+	l.SetLevel(logrus.DebugLevel)
+	l.SetOutput(w)
+	_ = l.Out
+	fl.WithField("k", "v").Infoln("x")
+
+	g := logrus.New()
+	e := g.WithField("k", "v")
+	e.Info("converted")
+	e.Infoln("not converted")
+
+	fields := logrus.Fields{"a": 1}
+	fields["k"] = "v"
+	logrus.WithFields(fields).Info("x")
+
+	ok := logrus.New()
+	ok.WithError(nil).Info("converted")
+
+	var h logrus.Fields = map[string]interface{}{}
+	ok.WithFields(h).Info("x")
+	logrus.WithFields(logrus.Fields{"k": "v"}).Info("converted")
+}
+
+func helper(e *logrus.Entry) *logrus.Entry {
+	return e.WithField("h", 1)
+}
+
+func useHelper() {
+	entry := helper(logrus.WithField("k", "v"))
+	_ = entry.Data
+}
+`))
+	c.Assert(err, qt.IsNil)
+
+	var report []string
+	for _, i := range issues {
+		report = append(report, i.String())
+	}
+	c.Assert(report, qt.DeepEquals, []string{
+		"u.go:13:26: logrus.Fields is only converted in composite literals; use logger.Fields with logger.FieldsSlog",
+		"u.go:14:15: s.log.Data has no counterpart for logger.TraceFieldLogger; log is not converted",
+		"u.go:18:4: l.SetLevel has no counterpart for logger.TraceFieldLogger; l is not converted",
+		"u.go:18:13: logrus.DebugLevel is not converted",
+		"u.go:19:4: l.SetOutput has no counterpart for logger.TraceFieldLogger; l is not converted",
+		"u.go:20:8: l.Out has no counterpart for logger.TraceFieldLogger; l is not converted",
+		"u.go:21:25: fl.WithField(\"k\", \"v\").Infoln has no counterpart for *logger.Slog; fl is not converted",
+		"u.go:26:4: e.Infoln has no counterpart for *logger.Slog; g is not converted",
+		"u.go:29:2: fields[\"k\"] has no counterpart for []any (logger.SlogFields); fields is not converted",
+		"u.go:35:8: logrus.Fields is only converted in composite literals; use logger.Fields with logger.FieldsSlog",
+		"u.go:36:2: ok.WithFields(h) passes fields that are not converted to *logger.Slog; ok is not converted",
+		"u.go:46:12: entry.Data has no counterpart for logger.TraceFieldLogger; the result of helper is not converted",
+	})
+
+	// the values with unsupported uses keep their logrus types, and so does the logrus import
+	c.Assert(string(out), qt.Equals, `package app
+
+import (
+	"io"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	log *logrus.Entry
+}
+
+func (s *Service) data() logrus.Fields {
+	return s.log.Data
+}
+
+func setup(w io.Writer, l *logrus.Logger, fl logrus.FieldLogger) {
+	l.SetLevel(logrus.DebugLevel)
+	l.SetOutput(w)
+	_ = l.Out
+	fl.WithField("k", "v").Infoln("x")
+
+	g := logrus.New()
+	e := g.WithField("k", "v")
+	e.Info("converted")
+	e.Infoln("not converted")
+
+	fields := logrus.Fields{"a": 1}
+	fields["k"] = "v"
+	logrus.WithFields(fields).Info("x")
+
+	ok := logrus.New()
+	ok.WithError(nil).Info("converted")
+
+	var h logrus.Fields = map[string]interface{}{}
+	ok.WithFields(h).Info("x")
+	logger.WithFields(logger.SlogFields("k", "v")).Info("converted")
+}
+
+func helper(e *logrus.Entry) *logrus.Entry {
+	return e.WithField("h", 1)
+}
+
+func useHelper() {
+	entry := helper(logrus.WithField("k", "v"))
+	_ = entry.Data
+}
+`)
+}
+
+func TestRun(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	name := filepath.Join(dir, "pkg", "a.go")
+	c.Assert(os.MkdirAll(filepath.Dir(name), 0o755), qt.IsNil)
+	c.Assert(os.WriteFile(name, []byte(testSource), 0o644), qt.IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(dir, "testdata"), 0o755), qt.IsNil)
+	c.Assert(os.WriteFile(filepath.Join(dir, "testdata", "b.go"), []byte(testSource), 0o644), qt.IsNil)
+
+	var stdout, stderr bytes.Buffer
+	c.Assert(run(dir, printTo(&stdout), &stderr), qt.IsNil)
+	c.Assert(stdout.String(), qt.Equals, testResult)
+	c.Assert(stderr.String(), qt.Contains, filepath.Join("pkg", "a.go")+":23:2: logrus.SetLevel is not converted\n")
+
+	stdout.Reset()
+	c.Assert(run(dir, writeFile, &stderr), qt.IsNil)
+	c.Assert(stdout.Len(), qt.Equals, 0)
+	b, err := os.ReadFile(name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, testResult)
+}
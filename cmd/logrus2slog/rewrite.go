@@ -0,0 +1,454 @@
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	logrusPath = "github.com/sirupsen/logrus"
+	loggerPath = "github.com/go-extras/go-kit/logger"
+	slogPath   = "log/slog"
+)
+
+// packageFuncs are the package-level functions of logrus that have a counterpart
+// with the same name and semantics in the logger package.
+var packageFuncs = map[string]bool{
+	"Print": true, "Printf": true,
+	"Trace": true, "Tracef": true,
+	"Debug": true, "Debugf": true,
+	"Info": true, "Infof": true,
+	"Warn": true, "Warnf": true,
+	"Warning": true, "Warningf": true,
+	"Error": true, "Errorf": true,
+	"Fatal": true, "Fatalf": true,
+	"Panic": true, "Panicf": true,
+	"WithField": true, "WithFields": true, "WithError": true, "WithContext": true,
+}
+
+// interfaceTypes maps the logrus types to the name of the logger interfaces replacing them.
+// All of them are instantiated with []any and *logger.Slog, the types used by Slog.
+var interfaceTypes = map[string]string{
+	"*Entry":          "TraceFieldLogger",
+	"*Logger":         "TraceFieldLogger",
+	"Ext1FieldLogger": "TraceFieldLogger",
+	"FieldLogger":     "FieldLogger",
+	"StdLogger":       "BasicLogger",
+}
+
+// issue is a logrus reference that could not be converted.
+type issue struct {
+	pos token.Position
+	msg string
+}
+
+func (i issue) String() string {
+	return fmt.Sprintf("%s: %s", i.pos, i.msg)
+}
+
+// rewriter rewrites the logrus references of a single file.
+type rewriter struct {
+	fset    *token.FileSet
+	file    *ast.File
+	logrus  string // the name logrus is imported as
+	logger  string // the name the logger package is imported as
+	keep    map[ast.Expr]bool
+	changed bool
+	useSlog bool
+
+	// newGroups are the paths of the imports added to a group of imports of another kind
+	newGroups []string
+}
+
+// rewriteFile rewrites the logrus references of file in place. It reports whether the file changed,
+// the references that could not be converted and the paths of the imports that must be moved
+// to their own group (see separateImports).
+func rewriteFile(fset *token.FileSet, file *ast.File) (bool, []issue, []string) {
+	r := &rewriter{fset: fset, file: file}
+
+	var spec *ast.ImportSpec
+	for _, s := range file.Imports {
+		if path, _ := strconv.Unquote(s.Path.Value); path == logrusPath {
+			spec = s
+		}
+	}
+	if spec == nil {
+		return false, nil, nil
+	}
+	r.logrus = "logrus"
+	if spec.Name != nil {
+		r.logrus = spec.Name.Name
+	}
+	if r.logrus == "." || r.logrus == "_" {
+		return false, []issue{{pos: fset.Position(spec.Pos()), msg: fmt.Sprintf("%s import of logrus is not supported", r.logrus)}}, nil
+	}
+	r.logger = "logger"
+	if identUsed(file, r.logger) {
+		r.logger = "kitlogger"
+	}
+
+	keep, issues := r.checkUses()
+	r.keep = keep
+	apply(file, r.rewrite)
+	issues = append(issues, r.remaining()...)
+	slices.SortStableFunc(issues, func(a, b issue) int { return a.pos.Offset - b.pos.Offset })
+	if !r.changed {
+		return false, issues, nil
+	}
+
+	if len(issues) == 0 {
+		deleteImport(file, logrusPath)
+	}
+	if r.logger == "logger" {
+		r.addImport("", loggerPath)
+	} else {
+		r.addImport(r.logger, loggerPath)
+	}
+	if r.useSlog {
+		r.addImport("", slogPath)
+	}
+	return true, issues, r.newGroups
+}
+
+// rewrite returns the replacement of e, or e itself if it is not a logrus reference
+// that can be converted or if it must be kept (see checkUses).
+func (r *rewriter) rewrite(e ast.Expr) ast.Expr {
+	if r.keep[e] {
+		return e
+	}
+	var repl ast.Expr
+	switch e := e.(type) {
+	case *ast.SelectorExpr:
+		repl = r.rewriteSelector(e)
+	case *ast.StarExpr:
+		repl = r.rewriteStar(e)
+	case *ast.CallExpr:
+		repl = r.rewriteCall(e)
+	case *ast.CompositeLit:
+		repl = r.rewriteFields(e)
+	}
+	if repl == nil {
+		return e
+	}
+	r.changed = true
+	return repl
+}
+
+// rewriteSelector returns the replacement of a logrus function or interface type, or nil.
+func (r *rewriter) rewriteSelector(e *ast.SelectorExpr) ast.Expr {
+	name, ok := r.logrusName(e)
+	if !ok {
+		return nil
+	}
+	if packageFuncs[name] {
+		return r.loggerSel(name)
+	}
+	if iface, ok := interfaceTypes[name]; ok {
+		return r.loggerInterface(iface)
+	}
+	return nil
+}
+
+// rewriteStar returns the replacement of a pointer to a logrus type, or nil.
+func (r *rewriter) rewriteStar(e *ast.StarExpr) ast.Expr {
+	if name, ok := r.logrusName(e.X); ok {
+		if iface, ok := interfaceTypes["*"+name]; ok {
+			return r.loggerInterface(iface)
+		}
+	}
+	return nil
+}
+
+// rewriteCall returns the replacement of logrus.New(), logger.NewSlog(slog.Default()), or nil.
+func (r *rewriter) rewriteCall(e *ast.CallExpr) ast.Expr {
+	if name, ok := r.logrusName(e.Fun); !ok || name != "New" || len(e.Args) > 0 {
+		return nil
+	}
+	r.useSlog = true
+	return &ast.CallExpr{
+		Fun:  r.loggerSel("NewSlog"),
+		Args: []ast.Expr{&ast.CallExpr{Fun: sel("slog", "Default")}},
+	}
+}
+
+// rewriteFields returns the replacement of a logrus.Fields{"k": v} literal,
+// logger.SlogFields("k", v), or nil.
+func (r *rewriter) rewriteFields(e *ast.CompositeLit) ast.Expr {
+	if name, ok := r.logrusName(e.Type); !ok || name != "Fields" || !convertibleFields(e) {
+		return nil
+	}
+	args := make([]ast.Expr, 0, 2*len(e.Elts))
+	for _, elt := range e.Elts {
+		kv, _ := elt.(*ast.KeyValueExpr) // checked by convertibleFields
+		args = append(args, kv.Key, kv.Value)
+	}
+	// keep the positions of the braces, so that the layout and the comments are preserved
+	return &ast.CallExpr{Fun: r.loggerSel("SlogFields"), Lparen: e.Lbrace, Args: args, Rparen: e.Rbrace}
+}
+
+// logrusName returns the name of the logrus identifier e refers to, if it does.
+func (r *rewriter) logrusName(e ast.Expr) (string, bool) {
+	s, ok := e.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	x, ok := s.X.(*ast.Ident)
+	// a package name is not resolved to a local object
+	if !ok || x.Name != r.logrus || x.Obj != nil {
+		return "", false
+	}
+	return s.Sel.Name, true
+}
+
+func (r *rewriter) loggerSel(name string) *ast.SelectorExpr {
+	return sel(r.logger, name)
+}
+
+// loggerInterface returns the logger interface iface instantiated for Slog.
+func (r *rewriter) loggerInterface(iface string) ast.Expr {
+	if iface == "BasicLogger" {
+		return r.loggerSel(iface)
+	}
+	return &ast.IndexListExpr{
+		X: r.loggerSel(iface),
+		Indices: []ast.Expr{
+			&ast.ArrayType{Elt: ast.NewIdent("any")},
+			&ast.StarExpr{X: r.loggerSel("Slog")},
+		},
+	}
+}
+
+// remaining returns the logrus references left after the rewrite, except the ones kept
+// because of unsupported uses, which are reported by checkUses.
+func (r *rewriter) remaining() []issue {
+	var issues []issue
+	ast.Inspect(r.file, func(n ast.Node) bool {
+		e, ok := n.(ast.Expr)
+		if !ok {
+			return true
+		}
+		if r.keep[e] {
+			return false
+		}
+		name, ok := r.logrusName(e)
+		if !ok {
+			return true
+		}
+		var msg string
+		switch {
+		case name == "New":
+			msg = "logrus.New is only converted when called"
+		case name == "Fields":
+			msg = "logrus.Fields is only converted in composite literals; use logger.Fields with logger.FieldsSlog"
+		case strings.HasSuffix(name, "ln"):
+			msg = fmt.Sprintf("logrus.%s has no counterpart; use logger.%s", name, strings.TrimSuffix(name, "ln"))
+		default:
+			msg = fmt.Sprintf("logrus.%s is not converted", name)
+		}
+		issues = append(issues, issue{pos: r.fset.Position(e.Pos()), msg: msg})
+		return false
+	})
+	return issues
+}
+
+// apply calls fn for every expression of the tree rooted at n, children first,
+// and replaces the expression with the result.
+func apply(n ast.Node, fn func(ast.Expr) ast.Expr) {
+	applyValue(reflect.ValueOf(n), fn)
+}
+
+var (
+	nodeType = reflect.TypeFor[ast.Node]()
+	exprType = reflect.TypeFor[ast.Expr]()
+)
+
+func applyValue(v reflect.Value, fn func(ast.Expr) ast.Expr) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		applyValue(v.Elem(), fn)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			applyField(v.Field(i), fn)
+		}
+	}
+}
+
+// applyField applies fn to the field f of a node, following only the fields holding nodes
+// (and thus not the objects and scopes, which form cycles).
+func applyField(f reflect.Value, fn func(ast.Expr) ast.Expr) {
+	switch {
+	case f.Kind() == reflect.Slice && f.Type().Elem().Implements(nodeType):
+		for i := 0; i < f.Len(); i++ {
+			applyField(f.Index(i), fn)
+		}
+	case f.Type() == exprType:
+		if f.IsNil() {
+			return
+		}
+		applyValue(f, fn)
+		f.Set(reflect.ValueOf(fn(f.Interface().(ast.Expr))))
+	case f.Type().Implements(nodeType):
+		applyValue(f, fn)
+	}
+}
+
+func sel(x, name string) *ast.SelectorExpr {
+	return &ast.SelectorExpr{X: ast.NewIdent(x), Sel: ast.NewIdent(name)}
+}
+
+// identUsed reports whether name is used as an identifier in file, other than as a selector.
+func identUsed(file *ast.File, name string) bool {
+	used := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && id.Name == name {
+					used = true
+				}
+				return !used
+			})
+			return false
+		case *ast.Ident:
+			used = used || n.Name == name
+		}
+		return !used
+	})
+	return used
+}
+
+// addImport adds an import of path to the file, if it is not imported yet. The import is added after
+// the last import of the same kind (standard library or not), so that it ends up in the same group.
+// If there is none, it is added first and recorded in newGroups, so that it is moved to a group
+// of its own once the file is formatted.
+func (r *rewriter) addImport(name, path string) {
+	file := r.file
+	for _, s := range file.Imports {
+		if p, _ := strconv.Unquote(s.Path.Value); p == path {
+			return
+		}
+	}
+	spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)}}
+	if name != "" {
+		spec.Name = ast.NewIdent(name)
+	}
+	file.Imports = append(file.Imports, spec)
+
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		if !gd.Lparen.IsValid() {
+			// turn a single import into a list, so that the new one can be added
+			gd.Lparen = gd.Specs[0].Pos()
+			gd.Rparen = gd.End()
+		}
+		at := 0
+		for i, s := range gd.Specs {
+			p, _ := strconv.Unquote(s.(*ast.ImportSpec).Path.Value)
+			if isStdImport(p) == isStdImport(path) {
+				at = i + 1
+			}
+		}
+		if at > 0 {
+			// place the import on the line of the previous one, so that no blank line separates them
+			spec.Path.ValuePos = gd.Specs[at-1].End()
+		} else {
+			r.newGroups = append(r.newGroups, path)
+		}
+		gd.Specs = slices.Insert(gd.Specs, at, ast.Spec(spec))
+		return
+	}
+	gd := &ast.GenDecl{Tok: token.IMPORT, Specs: []ast.Spec{spec}}
+	file.Decls = append([]ast.Decl{gd}, file.Decls...)
+}
+
+// isStdImport reports whether path is the path of a standard library package.
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// deleteImport removes the import of path from file.
+func deleteImport(file *ast.File, path string) {
+	isPath := func(s *ast.ImportSpec) bool {
+		p, _ := strconv.Unquote(s.Path.Value)
+		return p == path
+	}
+	file.Imports = slices.DeleteFunc(file.Imports, isPath)
+	for i, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		gd.Specs = slices.DeleteFunc(gd.Specs, func(s ast.Spec) bool { return isPath(s.(*ast.ImportSpec)) })
+		if len(gd.Specs) == 0 {
+			file.Decls = slices.Delete(file.Decls, i, i+1)
+			return
+		}
+	}
+}
+
+// separateImports moves the imports of paths in the import block of src to groups of their own:
+// before the other groups for the standard library packages, after them for the others.
+// The imports must be on lines of their own, as format.Node writes them.
+func separateImports(src []byte, paths []string) []byte {
+	lines := strings.Split(string(src), "\n")
+	start := slices.Index(lines, "import (")
+	if start < 0 || len(paths) == 0 {
+		return src
+	}
+	end := start + slices.Index(lines[start:], ")")
+
+	var std, other, rest []string
+	for _, line := range lines[start+1 : end] {
+		fields := strings.Fields(line)
+		path := ""
+		if len(fields) > 0 {
+			path, _ = strconv.Unquote(fields[len(fields)-1])
+		}
+		switch {
+		case !slices.Contains(paths, path):
+			rest = append(rest, line)
+		case isStdImport(path):
+			std = append(std, line)
+		default:
+			other = append(other, line)
+		}
+	}
+	rest = trimBlankLines(rest)
+
+	var block []string
+	for _, group := range [][]string{std, rest, other} {
+		if len(group) == 0 {
+			continue
+		}
+		if len(block) > 0 {
+			block = append(block, "")
+		}
+		block = append(block, group...)
+	}
+	out := slices.Concat(lines[:start+1], block, lines[end:])
+	return []byte(strings.Join(out, "\n"))
+}
+
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
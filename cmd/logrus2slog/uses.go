@@ -0,0 +1,548 @@
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+// kind describes the type a converted logrus value gets.
+type kind struct {
+	name string       // the name of the type in the reports
+	typ  reflect.Type // nil for the fields, which only support the operations of a slice
+}
+
+var (
+	slogKind   = &kind{name: "*logger.Slog", typ: reflect.TypeFor[*logger.Slog]()}
+	fieldsKind = &kind{name: "[]any (logger.SlogFields)"}
+
+	// interfaceKinds maps the names of interfaceTypes to their kinds.
+	interfaceKinds = map[string]*kind{
+		"TraceFieldLogger": {name: "logger.TraceFieldLogger", typ: reflect.TypeFor[logger.TraceFieldLogger[[]any, *logger.Slog]]()},
+		"FieldLogger":      {name: "logger.FieldLogger", typ: reflect.TypeFor[logger.FieldLogger[[]any, *logger.Slog]]()},
+		"BasicLogger":      {name: "logger.BasicLogger", typ: reflect.TypeFor[logger.BasicLogger]()},
+	}
+)
+
+// selector returns the kind of the result of selecting name on a value of kind k:
+// the kind of the result of the method name if it returns a *logger.Slog, nil otherwise.
+// ok is false if k has no such method or field.
+func (k *kind) selector(name string) (result *kind, ok bool) {
+	if k.typ == nil {
+		return nil, false
+	}
+	if m, ok := k.typ.MethodByName(name); ok {
+		if m.Type.NumOut() == 1 && m.Type.Out(0) == slogKind.typ {
+			return slogKind, true
+		}
+		return nil, true
+	}
+	if k.typ.Kind() == reflect.Pointer {
+		if f, ok := k.typ.Elem().FieldByName(name); ok && f.IsExported() {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// binding is a value that is converted from a logrus type: a variable, a parameter,
+// a struct field, the result of a function or an expression.
+//
+// The bindings whose values flow into each other, through assignments, arguments, results
+// or derivations, form a class: they are either all converted or all kept, so that
+// the rewritten code compiles.
+type binding struct {
+	name   string
+	kind   *kind
+	nodes  []ast.Expr // the logrus expressions to keep if the binding is not converted
+	parent *binding   // the binding the value is derived from, if any, which names it in the reports
+
+	class *binding // the representative of the class, or nil for the representative itself
+	kept  bool     // whether the class is kept, on the representative only
+}
+
+// representative returns the representative of the class of b.
+func (b *binding) representative() *binding {
+	for b.class != nil {
+		b = b.class
+	}
+	return b
+}
+
+// root returns the binding b derives from, which names b in the reports.
+func (b *binding) root() *binding {
+	for b.parent != nil {
+		b = b.parent
+	}
+	return b
+}
+
+// union merges the classes of a and b.
+func union(a, b *binding) {
+	a, b = a.representative(), b.representative()
+	if a == b {
+		return
+	}
+	b.class = a
+	a.kept = a.kept || b.kept
+}
+
+// value is the kind of an expression and the binding it derives from.
+type value struct {
+	kind    *kind
+	binding *binding
+}
+
+// signature holds the bindings of the parameters and results of a function declared in the file,
+// nil for the ones whose type is not converted.
+type signature struct {
+	params  []*binding
+	results []*binding
+}
+
+// usage finds the uses of the converted values that do not compile after the conversion.
+type usage struct {
+	r        *rewriter
+	bindings []*binding
+	objects  map[*ast.Object]*binding
+	fields   map[string]*binding // struct fields by name
+	funcs    map[*ast.Object]*signature
+	calls    map[ast.Expr]*binding
+	issues   []issue
+	reported map[token.Pos]bool
+}
+
+// checkUses finds the uses of the values converted from logrus types that the converted
+// types do not support, such as l.SetLevel for a *logrus.Logger or f["k"] for a logrus.Fields.
+// It reports them and returns the logrus expressions to keep, so that these values,
+// and the ones they flow into or from, are left unconverted.
+func (r *rewriter) checkUses() (map[ast.Expr]bool, []issue) {
+	u := &usage{
+		r:        r,
+		objects:  make(map[*ast.Object]*binding),
+		fields:   make(map[string]*binding),
+		funcs:    make(map[*ast.Object]*signature),
+		calls:    make(map[ast.Expr]*binding),
+		reported: make(map[token.Pos]bool),
+	}
+
+	// the declared types first, as the values may be declared before the types they use
+	ast.Inspect(r.file, u.declaration)
+	u.signatures()
+	ast.Inspect(r.file, u.definition)
+	ast.Inspect(r.file, u.flow)
+	ast.Inspect(r.file, u.use)
+
+	keep := make(map[ast.Expr]bool)
+	for _, b := range u.bindings {
+		if b.representative().kept {
+			for _, n := range b.nodes {
+				keep[n] = true
+			}
+		}
+	}
+	return keep, u.issues
+}
+
+// declaration records the bindings of the struct fields, parameters and variables
+// declared with a converted type.
+func (u *usage) declaration(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.StructType:
+		for _, f := range n.Fields.List {
+			if k := u.typeKind(f.Type); k != nil {
+				for _, name := range f.Names {
+					u.fields[name.Name] = u.newBinding(name.Name, k, f.Type)
+				}
+			}
+		}
+		return false
+	case *ast.Field:
+		u.declare(n.Names, n.Type)
+	case *ast.ValueSpec:
+		if n.Type != nil {
+			u.declare(n.Names, n.Type)
+		}
+	}
+	return true
+}
+
+// signatures records the signatures of the functions declared in the file. The methods are
+// not recorded, as their receivers are not resolved.
+func (u *usage) signatures() {
+	for _, decl := range u.r.file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil || fd.Name.Obj == nil {
+			continue
+		}
+		sig := &signature{params: u.fieldBindings(fd.Type.Params, "")}
+		if fd.Type.Results != nil {
+			sig.results = u.fieldBindings(fd.Type.Results, "the result of "+fd.Name.Name)
+		}
+		u.funcs[fd.Name.Obj] = sig
+	}
+}
+
+// fieldBindings returns the bindings of the parameters or results in list, one per value.
+// The unnamed ones with a converted type get a binding named name, if set.
+func (u *usage) fieldBindings(list *ast.FieldList, name string) []*binding {
+	var bindings []*binding
+	for _, f := range list.List {
+		if len(f.Names) == 0 {
+			var b *binding
+			if k := u.typeKind(f.Type); k != nil && name != "" {
+				b = u.newBinding(name, k, f.Type)
+			}
+			bindings = append(bindings, b)
+			continue
+		}
+		for _, id := range f.Names {
+			bindings = append(bindings, u.objects[id.Obj])
+		}
+	}
+	return bindings
+}
+
+// definition records the bindings of the variables defined without a type.
+func (u *usage) definition(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.ValueSpec:
+		if n.Type == nil {
+			u.defineAll(n.Names, n.Values)
+		}
+	case *ast.AssignStmt:
+		if n.Tok == token.DEFINE {
+			idents := make([]*ast.Ident, len(n.Lhs))
+			for i, lhs := range n.Lhs {
+				idents[i], _ = lhs.(*ast.Ident)
+			}
+			u.defineAll(idents, n.Rhs)
+		}
+	}
+	return true
+}
+
+// defineAll defines the variables names with the values vals, which may be a single call
+// returning several values.
+func (u *usage) defineAll(names []*ast.Ident, vals []ast.Expr) {
+	if len(names) == len(vals) {
+		for i, name := range names {
+			u.define(name, vals[i], u.valueOf(vals[i]))
+		}
+		return
+	}
+	if len(vals) != 1 {
+		return
+	}
+	results := u.results(vals[0])
+	if len(results) != len(names) {
+		return
+	}
+	for i, name := range names {
+		if results[i] != nil {
+			u.define(name, nil, value{kind: results[i].kind, binding: results[i]})
+		}
+	}
+}
+
+func (u *usage) define(name *ast.Ident, val ast.Expr, v value) {
+	if name == nil || name.Obj == nil || name.Name == "_" || v.kind == nil {
+		return
+	}
+	if val != nil && v.binding != nil && u.calls[ast.Unparen(val)] == v.binding {
+		// name the value after the variable rather than after the logrus expression
+		v.binding.name = name.Name
+	}
+	b := u.newBinding(name.Name, v.kind)
+	if v.binding != nil {
+		b.parent = v.binding
+		union(b, v.binding)
+	}
+	u.objects[name.Obj] = b
+}
+
+// flow merges the classes of the bindings whose values flow into each other.
+func (u *usage) flow(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.FuncDecl:
+		u.returns(n)
+	case *ast.AssignStmt:
+		if n.Tok == token.ASSIGN && len(n.Lhs) == len(n.Rhs) {
+			for i, lhs := range n.Lhs {
+				u.assign(u.valueOf(lhs), n.Rhs[i])
+			}
+		}
+	case *ast.ValueSpec:
+		if n.Type != nil && len(n.Names) == len(n.Values) {
+			for i, name := range n.Names {
+				u.assign(u.valueOf(name), n.Values[i])
+			}
+		}
+	case *ast.CallExpr:
+		u.arguments(n)
+	case *ast.KeyValueExpr:
+		// Service{log: l}
+		if key, ok := n.Key.(*ast.Ident); ok && u.fields[key.Name] != nil {
+			u.assign(value{binding: u.fields[key.Name]}, n.Value)
+		}
+	}
+	return true
+}
+
+// arguments merges the classes of the parameters of the function called by call
+// with the ones of the arguments, if the function is declared in the file.
+func (u *usage) arguments(call *ast.CallExpr) {
+	sig := u.signature(call.Fun)
+	if sig == nil {
+		return
+	}
+	for i, arg := range call.Args {
+		if i < len(sig.params) {
+			u.assign(value{binding: sig.params[i]}, arg)
+		}
+	}
+}
+
+// assign merges the class of dst with the one of the value of e, if both are converted.
+func (u *usage) assign(dst value, e ast.Expr) {
+	if src := u.valueOf(e); dst.binding != nil && src.binding != nil {
+		union(dst.binding, src.binding)
+	}
+}
+
+// returns merges the classes of the results of fd with the ones of the returned values.
+func (u *usage) returns(fd *ast.FuncDecl) {
+	sig := u.funcs[fd.Name.Obj]
+	if fd.Recv != nil || sig == nil || fd.Body == nil {
+		return
+	}
+	ast.Inspect(fd.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) != len(sig.results) {
+				return true
+			}
+			for i, res := range n.Results {
+				if sig.results[i] != nil {
+					u.assign(value{binding: sig.results[i]}, res)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// use reports the uses of the converted values that the converted types do not support.
+func (u *usage) use(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.SelectorExpr:
+		v := u.valueOf(n.X)
+		if v.kind == nil {
+			return true
+		}
+		if _, ok := v.kind.selector(n.Sel.Name); !ok {
+			u.unsupported(n, v, "%s has no counterpart for %s")
+		}
+	case *ast.IndexExpr:
+		if v := u.valueOf(n.X); v.kind == fieldsKind {
+			u.unsupported(n, v, "%s has no counterpart for %s")
+		}
+	case *ast.RangeStmt:
+		if v := u.valueOf(n.X); v.kind == fieldsKind {
+			u.unsupported(n.X, v, "%s has no counterpart for %s")
+		}
+	case *ast.CallExpr:
+		u.withFields(n)
+	}
+	return true
+}
+
+// withFields checks the fields passed to WithFields, which must be converted if the logger is,
+// and kept if it is not.
+func (u *usage) withFields(call *ast.CallExpr) {
+	name, ok := u.r.logrusName(call.Fun)
+	if sel, isSel := call.Fun.(*ast.SelectorExpr); !ok && isSel {
+		name = sel.Sel.Name
+	}
+	if name != "WithFields" || len(call.Args) != 1 {
+		return
+	}
+	recv := u.valueOf(call)
+	if recv.binding == nil {
+		return
+	}
+	arg := u.valueOf(call.Args[0])
+	switch {
+	case arg.kind == fieldsKind:
+		union(recv.binding, arg.binding)
+	case isNil(call.Args[0]):
+	default:
+		u.unsupported(call, recv, "%s passes fields that are not converted to %s")
+	}
+}
+
+// typeKind returns the kind a logrus type is converted to, if it is converted.
+func (u *usage) typeKind(typ ast.Expr) *kind {
+	name, ok := u.r.logrusName(typ)
+	if star, isStar := typ.(*ast.StarExpr); isStar {
+		name, ok = u.r.logrusName(star.X)
+		name = "*" + name
+	}
+	if !ok {
+		return nil
+	}
+	return interfaceKinds[interfaceTypes[name]]
+}
+
+func (u *usage) declare(names []*ast.Ident, typ ast.Expr) {
+	k := u.typeKind(typ)
+	if k == nil {
+		return
+	}
+	for _, name := range names {
+		if name.Obj != nil && u.objects[name.Obj] == nil {
+			u.objects[name.Obj] = u.newBinding(name.Name, k, typ)
+		}
+	}
+}
+
+func (u *usage) newBinding(name string, k *kind, nodes ...ast.Expr) *binding {
+	b := &binding{name: name, kind: k, nodes: nodes}
+	u.bindings = append(u.bindings, b)
+	return b
+}
+
+// signature returns the signature of the function fun refers to, if it is declared in the file.
+func (u *usage) signature(fun ast.Expr) *signature {
+	if id, ok := ast.Unparen(fun).(*ast.Ident); ok && id.Obj != nil {
+		return u.funcs[id.Obj]
+	}
+	return nil
+}
+
+// results returns the bindings of the results of e if it is a call to a function declared in the file.
+func (u *usage) results(e ast.Expr) []*binding {
+	if call, ok := ast.Unparen(e).(*ast.CallExpr); ok {
+		if sig := u.signature(call.Fun); sig != nil {
+			return sig.results
+		}
+	}
+	return nil
+}
+
+// valueOf returns the kind of e if it is a value converted from a logrus type.
+func (u *usage) valueOf(e ast.Expr) value {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return u.valueOf(e.X)
+	case *ast.Ident:
+		if b := u.objects[e.Obj]; e.Obj != nil && b != nil {
+			return value{kind: b.kind, binding: b}
+		}
+	case *ast.SelectorExpr:
+		return u.fieldValue(e)
+	case *ast.CompositeLit:
+		// logrus.Fields{"k": v}
+		if name, ok := u.r.logrusName(e.Type); ok && name == "Fields" && convertibleFields(e) {
+			return value{kind: fieldsKind, binding: u.call(e, u.r.logrus+".Fields{...}", fieldsKind, e)}
+		}
+	case *ast.CallExpr:
+		return u.callValue(e)
+	}
+	return value{}
+}
+
+// fieldValue returns the value of the struct field selected by e, if its type is converted.
+func (u *usage) fieldValue(e *ast.SelectorExpr) value {
+	b := u.fields[e.Sel.Name]
+	if b == nil || u.valueOf(e.X).kind != nil {
+		return value{}
+	}
+	if _, ok := u.r.logrusName(e); ok {
+		return value{}
+	}
+	return value{kind: b.kind, binding: b}
+}
+
+// callValue returns the value of the result of the call e, if its type is converted.
+func (u *usage) callValue(e *ast.CallExpr) value {
+	// logrus.New(), logrus.WithField(...), etc.
+	if name, ok := u.r.logrusName(e.Fun); ok {
+		switch {
+		case name == "New" && len(e.Args) == 0:
+			return value{kind: slogKind, binding: u.call(e, u.r.logrus+".New()", slogKind, e)}
+		case packageFuncs[name] && strings.HasPrefix(name, "With"):
+			return value{kind: slogKind, binding: u.call(e, u.r.logrus+"."+name+"(...)", slogKind, e.Fun)}
+		}
+		return value{}
+	}
+	// helper(...), for a function declared in the file
+	if results := u.results(e); len(results) == 1 && results[0] != nil {
+		return value{kind: results[0].kind, binding: results[0]}
+	}
+	// l.WithField(...), etc.
+	if sel, ok := e.Fun.(*ast.SelectorExpr); ok {
+		if v := u.valueOf(sel.X); v.kind != nil {
+			if k, _ := v.kind.selector(sel.Sel.Name); k != nil {
+				return value{kind: k, binding: v.binding}
+			}
+		}
+	}
+	return value{}
+}
+
+// call returns the binding of the logrus expression e, which is kept as node if the binding
+// is not converted.
+func (u *usage) call(e ast.Expr, name string, k *kind, node ast.Expr) *binding {
+	b := u.calls[e]
+	if b == nil {
+		b = u.newBinding(name, k, node)
+		u.calls[e] = b
+	}
+	return b
+}
+
+// unsupported reports the use e of the value v with the message format, which takes e and
+// the kind of v, and keeps the class of v.
+func (u *usage) unsupported(e ast.Expr, v value, format string) {
+	if v.binding == nil {
+		return
+	}
+	v.binding.representative().kept = true
+	if u.reported[e.Pos()] {
+		return
+	}
+	u.reported[e.Pos()] = true
+	pos := e.Pos()
+	if sel, ok := e.(*ast.SelectorExpr); ok {
+		pos = sel.Sel.Pos()
+	}
+	u.issues = append(u.issues, issue{
+		pos: u.r.fset.Position(pos),
+		msg: fmt.Sprintf(format, types.ExprString(e), v.kind.name) + fmt.Sprintf("; %s is not converted", v.binding.root().name),
+	})
+}
+
+// convertibleFields reports whether the logrus.Fields literal e is converted by rewrite.
+func convertibleFields(e *ast.CompositeLit) bool {
+	for _, elt := range e.Elts {
+		if _, ok := elt.(*ast.KeyValueExpr); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isNil(e ast.Expr) bool {
+	id, ok := ast.Unparen(e).(*ast.Ident)
+	return ok && id.Name == "nil" && id.Obj == nil
+}
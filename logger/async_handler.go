@@ -0,0 +1,251 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy defines what AsyncHandler does with a record when its queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes Handle wait until there is room in the queue
+	// or the context passed to Handle is done.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDrop makes Handle drop the record silently.
+	OverflowDrop
+
+	// OverflowDropCount makes Handle drop the record and the handler report the number of
+	// dropped records with a warning once the queue is drained:
+	//
+	//	level=WARN msg="dropped 12 messages" dropped=12
+	OverflowDropCount
+)
+
+// AsyncOptions are options for AsyncHandler.
+type AsyncOptions struct {
+	// QueueSize is the maximum number of records waiting to be written. Defaults to 1024.
+	QueueSize int
+
+	// Overflow is the policy applied when the queue is full. Defaults to OverflowBlock.
+	Overflow OverflowPolicy
+
+	// OnError is called by the background writer with the errors returned by the wrapped handler.
+	// Defaults to printing the error to stderr.
+	OnError func(err error)
+}
+
+var (
+	_ slog.Handler = (*AsyncHandler)(nil)
+	_ Flusher      = (*AsyncHandler)(nil)
+)
+
+// AsyncHandler is a slog.Handler wrapper that queues records and passes them to the wrapped
+// handler from a background goroutine, so that logging does not wait for slow sinks.
+//
+// Records are cloned when they are queued and the wrapped handler gets a context
+// that is never canceled, as the record may be written after the logging call returns.
+// Errors of the wrapped handler cannot be returned to the caller and are passed
+// to AsyncOptions.OnError instead.
+//
+// Fatal and Fatalf flush the handler before the process exits. Other programs should
+// call Close before exiting to write the queued records and stop the background goroutine.
+// After Close, records are passed to the wrapped handler synchronously.
+//
+// Handlers derived with WithAttrs and WithGroup share the queue of the original handler.
+type AsyncHandler struct {
+	next  slog.Handler
+	state *asyncState
+}
+
+// asyncEntry is an item of the queue: either a record to write or,
+// if done is not nil, a flush marker.
+type asyncEntry struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+	done    chan struct{}
+}
+
+type asyncState struct {
+	opts    AsyncOptions
+	root    slog.Handler
+	queue   chan asyncEntry
+	stopped chan struct{}
+	dropped atomic.Uint64
+	pending atomic.Uint64
+
+	// mu guards closed and prevents the queue from being closed while it is written to.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncHandler returns an AsyncHandler that passes records to next and starts its background goroutine.
+func NewAsyncHandler(next slog.Handler, opts AsyncOptions) *AsyncHandler {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(err error) {
+			fmt.Fprintln(os.Stderr, "logger: async handler failed to handle record:", err)
+		}
+	}
+	state := &asyncState{
+		opts:    opts,
+		root:    next,
+		queue:   make(chan asyncEntry, opts.QueueSize),
+		stopped: make(chan struct{}),
+	}
+	go state.run()
+
+	return &AsyncHandler{
+		next:  next,
+		state: state,
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle queues the record. When the queue is full, it applies the overflow policy.
+// With OverflowBlock, it returns the error of the context if it is done before the record is queued.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	s := h.state
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return h.next.Handle(ctx, r)
+	}
+
+	e := asyncEntry{ctx: context.WithoutCancel(ctx), handler: h.next, record: r.Clone()}
+	if s.opts.Overflow == OverflowBlock {
+		select {
+		case s.queue <- e:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case s.queue <- e:
+	default:
+		s.dropped.Add(1)
+		if s.opts.Overflow == OverflowDropCount {
+			s.pending.Add(1)
+		}
+	}
+	return nil
+}
+
+// WithAttrs returns a new AsyncHandler whose wrapped handler has the given attributes.
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{next: h.next.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a new AsyncHandler whose wrapped handler has the given group.
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{next: h.next.WithGroup(name), state: h.state}
+}
+
+// Flush waits until the records queued before the call are written and then flushes
+// the wrapped handler if it implements Flusher. It returns the error of the context
+// if it is done first.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	s := h.state
+	s.mu.RLock()
+	if !s.closed {
+		done := make(chan struct{})
+		select {
+		case s.queue <- asyncEntry{done: done}:
+		case <-ctx.Done():
+			s.mu.RUnlock()
+			return ctx.Err()
+		}
+		s.mu.RUnlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	} else {
+		s.mu.RUnlock()
+	}
+	return flushHandler(ctx, s.root)
+}
+
+// Close writes the queued records, stops the background goroutine and flushes the wrapped
+// handler if it implements Flusher. It returns the error of the context if it is done first,
+// in which case the remaining records are still written in the background.
+// Closing an already closed handler only flushes the wrapped handler.
+func (h *AsyncHandler) Close(ctx context.Context) error {
+	s := h.state
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return flushHandler(ctx, s.root)
+}
+
+// Dropped returns the number of records dropped so far because the queue was full.
+func (h *AsyncHandler) Dropped() uint64 {
+	return h.state.dropped.Load()
+}
+
+func (s *asyncState) run() {
+	defer close(s.stopped)
+
+	for e := range s.queue {
+		if e.done != nil {
+			s.reportDropped()
+			close(e.done)
+			continue
+		}
+		if err := e.handler.Handle(e.ctx, e.record); err != nil {
+			s.opts.OnError(err)
+		}
+		if len(s.queue) == 0 {
+			s.reportDropped()
+		}
+	}
+	s.reportDropped()
+}
+
+// reportDropped writes a warning with the number of records dropped since the last report, if any.
+func (s *asyncState) reportDropped() {
+	n := s.pending.Swap(0)
+	if n == 0 {
+		return
+	}
+	r := slog.NewRecord(time.Now(), slog.LevelWarn, fmt.Sprintf("dropped %d messages", n), 0)
+	r.AddAttrs(slog.Uint64("dropped", n))
+	ctx := context.Background()
+	if !s.root.Enabled(ctx, r.Level) {
+		return
+	}
+	if err := s.root.Handle(ctx, r); err != nil {
+		s.opts.OnError(err)
+	}
+}
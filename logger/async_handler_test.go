@@ -0,0 +1,146 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+// blockingHandler is a handler that waits for release before handling the first record.
+type blockingHandler struct {
+	slog.Handler
+	started chan struct{}
+	release chan struct{}
+	once    *sync.Once
+}
+
+func newBlockingHandler(next slog.Handler) blockingHandler {
+	return blockingHandler{
+		Handler: next,
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		once:    &sync.Once{},
+	}
+}
+
+func (h blockingHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.once.Do(func() {
+		close(h.started)
+		<-h.release
+	})
+	return h.Handler.Handle(ctx, r)
+}
+
+func TestAsyncHandler(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	var flushed int
+	h := logger.NewAsyncHandler(flushHandler{Handler: rec, flushed: &flushed}, logger.AsyncOptions{})
+	defer h.Close(context.Background())
+
+	l := slog.New(h).With("a", 1).WithGroup("g")
+	for i := 0; i < 100; i++ {
+		l.Info("message", "i", i)
+	}
+	c.Assert(h.Flush(context.Background()), qt.IsNil)
+	c.Assert(flushed, qt.Equals, 1)
+	c.Assert(rec.Len(), qt.Equals, 100)
+	c.Assert(rec.Entries()[99].Matches(slog.LevelInfo, "message", slog.Int("a", 1), slog.Int("g.i", 99)), qt.IsTrue)
+}
+
+func TestAsyncHandler_Drop(t *testing.T) {
+	c := qt.New(t)
+
+	for _, policy := range []logger.OverflowPolicy{logger.OverflowDrop, logger.OverflowDropCount} {
+		rec := loggertest.New()
+		next := newBlockingHandler(rec)
+		h := logger.NewAsyncHandler(next, logger.AsyncOptions{QueueSize: 1, Overflow: policy})
+		l := slog.New(h)
+
+		l.Info("first")
+		<-next.started
+		l.Info("second")
+		l.Info("third")
+		l.Info("fourth")
+		c.Assert(h.Dropped(), qt.Equals, uint64(2))
+
+		close(next.release)
+		c.Assert(h.Close(context.Background()), qt.IsNil)
+
+		rec.AssertEntry(t, slog.LevelInfo, "first")
+		rec.AssertEntry(t, slog.LevelInfo, "second")
+		rec.AssertNoEntry(t, slog.LevelInfo, "third")
+		if policy == logger.OverflowDropCount {
+			c.Assert(rec.Len(), qt.Equals, 3)
+			rec.AssertEntry(t, slog.LevelWarn, "dropped 2 messages", slog.Uint64("dropped", 2))
+		} else {
+			c.Assert(rec.Len(), qt.Equals, 2)
+		}
+	}
+}
+
+func TestAsyncHandler_Block(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	next := newBlockingHandler(rec)
+	h := logger.NewAsyncHandler(next, logger.AsyncOptions{QueueSize: 1})
+	l := slog.New(h)
+
+	l.Info("first")
+	<-next.started
+	l.Info("second")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.Assert(h.Handle(ctx, slog.NewRecord(testTime, slog.LevelInfo, "third", 0)), qt.ErrorIs, context.Canceled)
+	c.Assert(h.Flush(ctx), qt.ErrorIs, context.Canceled)
+
+	close(next.release)
+	c.Assert(h.Flush(context.Background()), qt.IsNil)
+	c.Assert(rec.Len(), qt.Equals, 2)
+	c.Assert(h.Dropped(), qt.Equals, uint64(0))
+}
+
+func TestAsyncHandler_Close(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	var errs []error
+	h := logger.NewAsyncHandler(failingHandler{Handler: rec}, logger.AsyncOptions{
+		OnError: func(err error) { errs = append(errs, err) },
+	})
+	l := slog.New(h)
+
+	l.Info("queued")
+	c.Assert(h.Close(context.Background()), qt.IsNil)
+	c.Assert(errs, qt.HasLen, 1)
+	c.Assert(errs[0], qt.ErrorMatches, "sink failed")
+
+	// records are handled synchronously after Close
+	c.Assert(h.Handle(context.Background(), slog.NewRecord(testTime, slog.LevelInfo, "late", 0)), qt.ErrorMatches, "sink failed")
+	c.Assert(h.Flush(context.Background()), qt.IsNil)
+	c.Assert(h.Close(context.Background()), qt.IsNil)
+}
+
+func TestAsyncHandler_Fatal(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	h := logger.NewAsyncHandler(rec, logger.AsyncOptions{})
+	defer h.Close(context.Background())
+
+	var entries int
+	sl := logger.NewSlog(slog.New(h)).WithExitFunc(func(int) { entries = rec.Len() })
+	sl.Info("before")
+	sl.Fatal("fatal")
+
+	c.Assert(entries, qt.Equals, 2)
+}
@@ -0,0 +1,87 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"log/slog"
+	"slices"
+)
+
+var _ error = (*AttrError)(nil)
+
+// AttrError is the error returned by ErrorfErr and WrapErr. It carries the attributes
+// of the log record written along with it (the fields attached to the logger and the attributes
+// passed to WrapErr), so that the code handling the error has the same context as the record,
+// and can log them once at the top level with AttrsFromError instead of logging at every level.
+type AttrError struct {
+	// Message is the message of the error, which prefixes the message of Err, if any.
+	Message string
+	Err     error
+	Attrs   []slog.Attr
+}
+
+// Error returns the message of the error followed by the message of the wrapped error, if any.
+func (e *AttrError) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Err.Error()
+	default:
+		return e.Message + ": " + e.Err.Error()
+	}
+}
+
+// Unwrap returns the wrapped error.
+func (e *AttrError) Unwrap() error {
+	return e.Err
+}
+
+// AttrsFromError returns the attributes carried by the AttrErrors in the chain of err,
+// from the innermost to the outermost one. If several of them carry an attribute with the same key,
+// which happens when the errors were returned by the same logger, only the innermost one is kept.
+// It returns nil if there are none.
+func AttrsFromError(err error) []slog.Attr {
+	if err == nil {
+		return nil
+	}
+	chain := append([]error{err}, unwrapChain(nil, err)...)
+	var attrs []slog.Attr
+	seen := make(map[string]bool)
+	for _, e := range slices.Backward(chain) {
+		ae, ok := e.(*AttrError)
+		if !ok {
+			continue
+		}
+		for _, a := range ae.Attrs {
+			if !seen[a.Key] {
+				seen[a.Key] = true
+				attrs = append(attrs, a)
+			}
+		}
+	}
+	return attrs
+}
+
+// attrError returns the error returned by ErrorfErr and WrapErr.
+func (sk sink) attrError(msg string, err error, attrs []slog.Attr) *AttrError {
+	return &AttrError{
+		Message: msg,
+		Err:     err,
+		Attrs:   slices.Concat(sk.attrs, attrs),
+	}
+}
+
+// argsToAttrs converts key-value pairs and slog.Attr values to attributes, as slog.Logger.Log does.
+func argsToAttrs(args []any) []slog.Attr {
+	var rec slog.Record
+	rec.Add(args...)
+	attrs := make([]slog.Attr, 0, rec.NumAttrs())
+	rec.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
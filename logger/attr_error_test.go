@@ -0,0 +1,107 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+// dropTime removes the time from the records, so that the output is deterministic.
+func dropTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+func TestSlog_ErrorfErr(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{ReplaceAttr: dropTime})))
+	err := sl.WithField("k", "v").ErrorfErr("read %s: %w", "config", io.EOF)
+
+	c.Assert(err, qt.ErrorMatches, "read config: EOF")
+	c.Assert(err, qt.ErrorIs, io.EOF)
+	var ae *logger.AttrError
+	c.Assert(errors.As(err, &ae), qt.IsTrue)
+	c.Assert(ae.Attrs, qt.DeepEquals, []slog.Attr{slog.String("k", "v")})
+	c.Assert(b.String(), qt.Equals, "level=ERROR msg=\"read config: EOF\" k=v\n")
+}
+
+func TestSlog_WrapErr(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{ReplaceAttr: dropTime}))).WithField("k", "v")
+	err := sl.WrapErr(io.EOF, "read failed", "path", "/etc/app", slog.Int("n", 1))
+
+	c.Assert(err, qt.ErrorMatches, "read failed: EOF")
+	c.Assert(err, qt.ErrorIs, io.EOF)
+	c.Assert(logger.AttrsFromError(err), qt.DeepEquals, []slog.Attr{
+		slog.String("k", "v"),
+		slog.String("path", "/etc/app"),
+		slog.Int("n", 1),
+	})
+	c.Assert(b.String(), qt.Equals, "level=ERROR msg=\"read failed\" k=v path=/etc/app n=1 error.msg=EOF error.type=*errors.errorString\n")
+
+	b.Reset()
+	c.Assert(sl.WrapErr(nil, "read failed"), qt.IsNil)
+	c.Assert(b.String(), qt.Equals, "")
+}
+
+func TestSlog_WrapErr_Disabled(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: logger.SlogLevelFatal})))
+	err := sl.WithField("k", "v").WrapErr(io.EOF, "read failed", "n", 1)
+
+	c.Assert(b.String(), qt.Equals, "")
+	c.Assert(logger.AttrsFromError(err), qt.DeepEquals, []slog.Attr{slog.String("k", "v"), slog.Int("n", 1)})
+}
+
+func TestWrapErr(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{ReplaceAttr: dropTime})))
+
+	err := logger.WrapErr(logger.ErrorfErr("test %d", 1), "failed", "k", "v")
+	c.Assert(err, qt.ErrorMatches, "failed: test 1")
+	c.Assert(logger.AttrsFromError(err), qt.DeepEquals, []slog.Attr{slog.String("k", "v")})
+	c.Assert(b.String(), qt.Equals, "level=ERROR msg=\"test 1\"\nlevel=ERROR msg=failed k=v error.msg=\"test 1\" error.type=*logger.AttrError error.chain.0.msg=\"test 1\" error.chain.0.type=*errors.errorString\n")
+}
+
+func TestAttrsFromError(t *testing.T) {
+	c := qt.New(t)
+
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(io.Discard, nil))).WithField("request", 42)
+	inner := sl.WrapErr(io.EOF, "read failed", "path", "/etc/app", "attempt", 1)
+	outer := fmt.Errorf("load: %w", sl.WrapErr(inner, "load failed", "attempt", 2))
+
+	c.Assert(outer, qt.ErrorMatches, "load: load failed: read failed: EOF")
+	// the innermost attributes come first and win
+	c.Assert(logger.AttrsFromError(outer), qt.DeepEquals, []slog.Attr{
+		slog.Int("request", 42),
+		slog.String("path", "/etc/app"),
+		slog.Int("attempt", 1),
+	})
+
+	joined := errors.Join(
+		&logger.AttrError{Message: "a", Attrs: []slog.Attr{slog.Int("a", 1)}},
+		&logger.AttrError{Message: "b", Attrs: []slog.Attr{slog.Int("b", 2)}},
+	)
+	c.Assert(logger.AttrsFromError(joined), qt.DeepEquals, []slog.Attr{slog.Int("b", 2), slog.Int("a", 1)})
+
+	c.Assert(logger.AttrsFromError(io.EOF), qt.IsNil)
+	c.Assert(logger.AttrsFromError(nil), qt.IsNil)
+}
@@ -0,0 +1,405 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The log formats supported by Config.
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatLogrusText = "logrus-text"
+	FormatLogrusJSON = "logrus-json"
+	FormatConsole    = "console"
+)
+
+// The output types supported by OutputConfig.
+const (
+	OutputStderr   = "stderr"
+	OutputStdout   = "stdout"
+	OutputFile     = "file"
+	OutputRotating = "rotating"
+)
+
+// Duration is a time.Duration that is read from and written to config files and env vars
+// in the format of time.ParseDuration, e.g. "1m30s".
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(data []byte) error {
+	v, err := time.ParseDuration(string(data))
+	if err != nil {
+		return fmt.Errorf("logger: duration %q: %w", data, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Config is the declarative configuration of a logger built with FromConfig.
+// It can be read from JSON (see LoadConfigJSON) or env vars (see ConfigFromEnv).
+// The zero value is a valid configuration: info level, text format, written to stderr.
+type Config struct {
+	// Level is the minimum level of the records. Defaults to INFO.
+	Level Level `json:"level"`
+
+	// Format is the format of the records: "text" (default), "json", "logrus-text", "logrus-json"
+	// or "console" (see ConsoleHandler).
+	Format string `json:"format"`
+
+	// Outputs are the destinations of the records. Defaults to stderr.
+	Outputs []OutputConfig `json:"outputs"`
+
+	// AddSource adds the source location of the logging calls to the records.
+	AddSource bool `json:"add_source"`
+
+	// TimeFormat is the layout of the timestamps, e.g. time.RFC3339Nano. Defaults to the one of the format.
+	TimeFormat string `json:"time_format"`
+
+	// Redact configures the redaction of secrets (see RedactHandler).
+	Redact *RedactConfig `json:"redact"`
+
+	// Sampling configures the sampling of the records (see SamplingHandler).
+	Sampling *SamplingConfig `json:"sampling"`
+}
+
+// OutputConfig is the configuration of a destination of the records.
+type OutputConfig struct {
+	// Type is the type of the output: "stderr", "stdout", "file" or "rotating".
+	Type string `json:"type"`
+
+	// Path is the path of the file of the "file" and "rotating" outputs.
+	Path string `json:"path"`
+
+	// MaxSize, RotateEvery, MaxBackups and Compress configure the "rotating" output (see RotateOptions).
+	MaxSize     int64    `json:"max_size"`
+	RotateEvery Duration `json:"rotate_every"`
+	MaxBackups  int      `json:"max_backups"`
+	Compress    bool     `json:"compress"`
+}
+
+// RedactConfig is the configuration of the redaction of secrets (see RedactOptions).
+type RedactConfig struct {
+	Keys        []string `json:"keys"`
+	Values      []string `json:"values"` // regular expressions
+	Replacement string   `json:"replacement"`
+}
+
+// SamplingConfig is the configuration of the sampling of the records (see SamplingOptions).
+type SamplingConfig struct {
+	Tick       Duration `json:"tick"`
+	First      int      `json:"first"`
+	Thereafter int      `json:"thereafter"`
+}
+
+// LoadConfigJSON reads a Config from the JSON document in r. Unknown fields are rejected.
+func LoadConfigJSON(r io.Reader) (Config, error) {
+	var cfg Config
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("logger: failed to decode config: %w", err)
+	}
+	return cfg, nil
+}
+
+// ConfigFromEnv reads a Config from the env vars starting with prefix, e.g. "APP_LOG_":
+//
+//	LEVEL               the level, e.g. "debug"
+//	FORMAT              the format, e.g. "json"
+//	OUTPUTS             a comma-separated list of outputs: "stderr", "stdout", "file:<path>" or "rotating:<path>"
+//	ADD_SOURCE          a boolean
+//	TIME_FORMAT         the layout of the timestamps
+//	ROTATE_MAX_SIZE     the maximum size of the rotating outputs, in bytes
+//	ROTATE_EVERY        the interval at which the rotating outputs are rotated, e.g. "24h"
+//	ROTATE_MAX_BACKUPS  the number of rotated files to keep
+//	ROTATE_COMPRESS     a boolean
+//	REDACT_KEYS         a comma-separated list of key patterns
+//	REDACT_VALUES       a regular expression matching secret values
+//	REDACT_REPLACEMENT  the replacement of the redacted values
+//	SAMPLING_TICK       the sampling interval, e.g. "1s"
+//	SAMPLING_FIRST      the number of records passed through per tick
+//	SAMPLING_THEREAFTER the sampling rate after the first records
+//
+// Unset variables keep their zero value.
+func ConfigFromEnv(prefix string) (Config, error) {
+	env := envReader{prefix: prefix}
+	var cfg Config
+
+	env.text("LEVEL", &cfg.Level)
+	cfg.Format = env.string("FORMAT")
+	for _, output := range env.list("OUTPUTS") {
+		typ, path, _ := strings.Cut(output, ":")
+		cfg.Outputs = append(cfg.Outputs, OutputConfig{Type: typ, Path: path})
+	}
+	cfg.AddSource = env.bool("ADD_SOURCE")
+	cfg.TimeFormat = env.string("TIME_FORMAT")
+
+	var rotate OutputConfig
+	rotate.MaxSize = int64(env.int("ROTATE_MAX_SIZE"))
+	env.text("ROTATE_EVERY", &rotate.RotateEvery)
+	rotate.MaxBackups = env.int("ROTATE_MAX_BACKUPS")
+	rotate.Compress = env.bool("ROTATE_COMPRESS")
+	for i := range cfg.Outputs {
+		if cfg.Outputs[i].Type == OutputRotating {
+			rotate.Type, rotate.Path = cfg.Outputs[i].Type, cfg.Outputs[i].Path
+			cfg.Outputs[i] = rotate
+		}
+	}
+
+	if keys, values := env.list("REDACT_KEYS"), env.string("REDACT_VALUES"); len(keys) > 0 || values != "" {
+		cfg.Redact = &RedactConfig{Keys: keys, Replacement: env.string("REDACT_REPLACEMENT")}
+		if values != "" {
+			cfg.Redact.Values = []string{values}
+		}
+	}
+
+	if first, thereafter := env.int("SAMPLING_FIRST"), env.int("SAMPLING_THEREAFTER"); first > 0 || thereafter > 0 {
+		cfg.Sampling = &SamplingConfig{First: first, Thereafter: thereafter}
+		env.text("SAMPLING_TICK", &cfg.Sampling.Tick)
+	}
+
+	if err := errors.Join(env.errs...); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// envReader reads env vars with a prefix, collecting the parsing errors.
+type envReader struct {
+	prefix string
+	errs   []error
+}
+
+func (e *envReader) string(name string) string {
+	return strings.TrimSpace(os.Getenv(e.prefix + name))
+}
+
+func (e *envReader) list(name string) []string {
+	var items []string
+	for _, item := range strings.Split(e.string(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (e *envReader) bool(name string) bool {
+	s := e.string(name)
+	if s == "" {
+		return false
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("logger: env var %s: %w", e.prefix+name, err))
+	}
+	return v
+}
+
+func (e *envReader) int(name string) int {
+	s := e.string(name)
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("logger: env var %s: %w", e.prefix+name, err))
+	}
+	return v
+}
+
+func (e *envReader) text(name string, v interface{ UnmarshalText([]byte) error }) {
+	s := e.string(name)
+	if s == "" {
+		return
+	}
+	if err := v.UnmarshalText([]byte(s)); err != nil {
+		e.errs = append(e.errs, fmt.Errorf("logger: env var %s: %w", e.prefix+name, err))
+	}
+}
+
+// FromConfig builds a logger from cfg. The handler writes the records in the configured format
+// to all the outputs, with level names known to this package (see ReplaceLevelAttr);
+// it is wrapped in a SamplingHandler and a RedactHandler if configured.
+// Every output has its own handler, so that an output failing does not prevent the records
+// from reaching the other ones (see MultiHandler).
+//
+// The files opened for the outputs stay open until the process exits;
+// use FromConfigWithCloser to be able to close them.
+func FromConfig(cfg Config) (*Slog, error) {
+	sl, _, err := FromConfigWithCloser(cfg)
+	return sl, err
+}
+
+// FromConfigWithCloser is like FromConfig, but it also returns an io.Closer that closes the files
+// opened for the outputs; the logger must not be used once it is closed. If FromConfigWithCloser
+// fails, the files it opened are already closed.
+func FromConfigWithCloser(cfg Config) (*Slog, io.Closer, error) {
+	// validate the configuration before opening the outputs where possible
+	switch cfg.Format {
+	case "", FormatText, FormatJSON, FormatLogrusText, FormatLogrusJSON, FormatConsole:
+	default:
+		return nil, nil, fmt.Errorf("logger: unknown log format %q", cfg.Format)
+	}
+	var redact RedactOptions
+	if cfg.Redact != nil {
+		redact = RedactOptions{Keys: cfg.Redact.Keys, Replacement: cfg.Redact.Replacement}
+		for _, expr := range cfg.Redact.Values {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, nil, fmt.Errorf("logger: redacted value expression %q: %w", expr, err)
+			}
+			redact.Values = append(redact.Values, re)
+		}
+	}
+
+	writers, closer, err := openOutputs(cfg.Outputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	// a handler per output rather than an io.MultiWriter, so that an output failing
+	// does not prevent the records from reaching the others
+	sinks := make([]slog.Handler, len(writers))
+	for i, w := range writers {
+		sinks[i] = newConfigHandler(w, cfg)
+	}
+	h := sinks[0]
+	if len(sinks) > 1 {
+		h = NewMultiHandler(sinks...)
+	}
+	if cfg.Sampling != nil {
+		h = NewSamplingHandler(h, SamplingOptions{
+			Tick:       time.Duration(cfg.Sampling.Tick),
+			First:      cfg.Sampling.First,
+			Thereafter: cfg.Sampling.Thereafter,
+		})
+	}
+	if cfg.Redact != nil {
+		rh, err := NewRedactHandler(h, redact)
+		if err != nil {
+			_ = closer.Close()
+			return nil, nil, err
+		}
+		h = rh
+	}
+	return NewSlog(slog.New(h)), closer, nil
+}
+
+// newConfigHandler returns the handler writing the records to w in the format of cfg,
+// which must be valid.
+func newConfigHandler(w io.Writer, cfg Config) slog.Handler {
+	switch cfg.Format {
+	case "", FormatText, FormatJSON:
+		opts := &slog.HandlerOptions{
+			Level:       cfg.Level,
+			AddSource:   cfg.AddSource,
+			ReplaceAttr: ReplaceLevelAttr,
+		}
+		if cfg.TimeFormat != "" {
+			opts.ReplaceAttr = ReplaceAttrs(ReplaceLevelAttr, replaceTimeAttr(cfg.TimeFormat))
+		}
+		if cfg.Format == FormatJSON {
+			return slog.NewJSONHandler(w, opts)
+		}
+		return slog.NewTextHandler(w, opts)
+	case FormatConsole:
+		return NewConsoleHandler(w, &ConsoleHandlerOptions{
+			Level:      cfg.Level,
+			AddSource:  cfg.AddSource,
+			TimeFormat: cfg.TimeFormat,
+		})
+	default:
+		opts := &LogrusHandlerOptions{
+			Level:           cfg.Level,
+			AddSource:       cfg.AddSource,
+			TimestampFormat: cfg.TimeFormat,
+		}
+		if cfg.Format == FormatLogrusJSON {
+			return NewLogrusJSONHandler(w, opts)
+		}
+		return NewLogrusTextHandler(w, opts)
+	}
+}
+
+// replaceTimeAttr returns a ReplaceAttr function formatting the time of the records with layout.
+func replaceTimeAttr(layout string) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+			a.Value = slog.StringValue(a.Value.Time().Format(layout))
+		}
+		return a
+	}
+}
+
+// openOutputs returns the writers of the outputs and the closer of the files it opened.
+func openOutputs(outputs []OutputConfig) ([]io.Writer, io.Closer, error) {
+	if len(outputs) == 0 {
+		return []io.Writer{os.Stderr}, outputClosers(nil), nil
+	}
+
+	writers := make([]io.Writer, 0, len(outputs))
+	var closers outputClosers
+	for _, o := range outputs {
+		switch o.Type {
+		case OutputStderr:
+			writers = append(writers, os.Stderr)
+		case OutputStdout:
+			writers = append(writers, os.Stdout)
+		case OutputFile:
+			f, err := os.OpenFile(o.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				_ = closers.Close()
+				return nil, nil, fmt.Errorf("logger: failed to open log file: %w", err)
+			}
+			writers = append(writers, f)
+			closers = append(closers, f)
+		case OutputRotating:
+			f, err := OpenRotatingFile(o.Path, RotateOptions{
+				MaxSize:     o.MaxSize,
+				RotateEvery: time.Duration(o.RotateEvery),
+				MaxBackups:  o.MaxBackups,
+				Compress:    o.Compress,
+			})
+			if err != nil {
+				_ = closers.Close()
+				return nil, nil, err
+			}
+			writers = append(writers, f)
+			closers = append(closers, f)
+		default:
+			_ = closers.Close()
+			return nil, nil, fmt.Errorf("logger: unknown log output %q", o.Type)
+		}
+	}
+	return writers, closers, nil
+}
+
+// outputClosers closes the files opened for the outputs.
+type outputClosers []io.Closer
+
+// Close closes all the files, returning the errors joined.
+func (c outputClosers) Close() error {
+	var errs []error
+	for _, closer := range c {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,185 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func TestFromConfig(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	cfg, err := logger.LoadConfigJSON(strings.NewReader(`{
+		"level": "trace",
+		"format": "json",
+		"outputs": [
+			{"type": "file", "path": "` + filepath.ToSlash(filepath.Join(dir, "app.log")) + `"},
+			{"type": "rotating", "path": "` + filepath.ToSlash(filepath.Join(dir, "rotating.log")) + `", "max_size": 1048576, "rotate_every": "24h"}
+		],
+		"time_format": "2006",
+		"redact": {"keys": ["*password*"], "values": ["\\d{4}-\\d{4}"]},
+		"sampling": {"tick": "1m", "first": 2}
+	}`))
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Outputs[1].RotateEvery, qt.Equals, logger.Duration(24*time.Hour))
+
+	sl, closer, err := logger.FromConfigWithCloser(cfg)
+	c.Assert(err, qt.IsNil)
+	sl.WithField("db_password", "x").Tracef("card %s", "1234-5678")
+	sl.Trace("sampled")
+	sl.Trace("sampled")
+	sl.Trace("sampled")
+	c.Assert(closer.Close(), qt.IsNil)
+
+	year := time.Now().Format("2006")
+	for _, name := range []string{"app.log", "rotating.log"} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, `{"time":"`+year+`","level":"TRACE","msg":"card 1234-5678","db_password":"[REDACTED]"}
+{"time":"`+year+`","level":"TRACE","msg":"sampled"}
+{"time":"`+year+`","level":"TRACE","msg":"sampled"}
+`)
+	}
+}
+
+func TestFromConfig_Default(t *testing.T) {
+	c := qt.New(t)
+
+	sl, err := logger.FromConfig(logger.Config{})
+	c.Assert(err, qt.IsNil)
+	c.Assert(sl.Enabled(context.Background(), slog.LevelInfo), qt.IsTrue)
+	c.Assert(sl.Enabled(context.Background(), slog.LevelDebug), qt.IsFalse)
+}
+
+func TestFromConfig_Logrus(t *testing.T) {
+	c := qt.New(t)
+
+	name := filepath.Join(t.TempDir(), "app.log")
+	sl, closer, err := logger.FromConfigWithCloser(logger.Config{
+		Format:     logger.FormatLogrusText,
+		Outputs:    []logger.OutputConfig{{Type: logger.OutputFile, Path: name}},
+		TimeFormat: "2006",
+	})
+	c.Assert(err, qt.IsNil)
+	sl.Debug("hidden")
+	sl.WithField("k", "v").Warn("shown")
+	c.Assert(closer.Close(), qt.IsNil)
+
+	b, err := os.ReadFile(name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `time=`+time.Now().Format("2006")+` level=warning msg=shown k=v`+"\n")
+}
+
+func TestFromConfig_FailingOutput(t *testing.T) {
+	c := qt.New(t)
+
+	// writes to /dev/full fail with ENOSPC
+	if _, err := os.Stat("/dev/full"); err != nil {
+		c.Skip("no /dev/full")
+	}
+	name := filepath.Join(t.TempDir(), "app.log")
+	sl, closer, err := logger.FromConfigWithCloser(logger.Config{
+		Outputs: []logger.OutputConfig{
+			{Type: logger.OutputFile, Path: "/dev/full"},
+			{Type: logger.OutputFile, Path: name},
+		},
+		TimeFormat: "2006",
+	})
+	c.Assert(err, qt.IsNil)
+	sl.Info("shown")
+	c.Assert(closer.Close(), qt.IsNil)
+
+	b, err := os.ReadFile(name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `time=`+time.Now().Format("2006")+` level=INFO msg=shown`+"\n")
+}
+
+func TestFromConfig_Errors(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		cfg logger.Config
+		err string
+	}{
+		{logger.Config{Format: "xml"}, `logger: unknown log format "xml"`},
+		{logger.Config{Outputs: []logger.OutputConfig{{Type: "syslog"}}}, `logger: unknown log output "syslog"`},
+		{logger.Config{Redact: &logger.RedactConfig{Values: []string{"("}}}, `logger: redacted value expression "\(": .*`},
+		{logger.Config{Redact: &logger.RedactConfig{Keys: []string{"["}}}, `logger: redacted key pattern "\[": .*`},
+		{logger.Config{Outputs: []logger.OutputConfig{{Type: "file", Path: t.TempDir()}}}, `logger: failed to open log file: .*`},
+		{logger.Config{Outputs: []logger.OutputConfig{{Type: "file", Path: filepath.Join(t.TempDir(), "app.log")}, {Type: "syslog"}}}, `logger: unknown log output "syslog"`},
+	}
+	for _, tt := range tests {
+		sl, closer, err := logger.FromConfigWithCloser(tt.cfg)
+		c.Assert(err, qt.ErrorMatches, tt.err)
+		c.Assert(sl, qt.IsNil)
+		c.Assert(closer, qt.IsNil)
+
+		sl, err = logger.FromConfig(tt.cfg)
+		c.Assert(err, qt.ErrorMatches, tt.err)
+		c.Assert(sl, qt.IsNil)
+	}
+
+	_, err := logger.LoadConfigJSON(strings.NewReader(`{"levle": "debug"}`))
+	c.Assert(err, qt.ErrorMatches, `logger: failed to decode config: json: unknown field "levle"`)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	c := qt.New(t)
+
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+	t.Setenv("TEST_LOG_FORMAT", "logrus-json")
+	t.Setenv("TEST_LOG_OUTPUTS", "stdout, rotating:/var/log/app.log")
+	t.Setenv("TEST_LOG_ADD_SOURCE", "true")
+	t.Setenv("TEST_LOG_ROTATE_EVERY", "1h")
+	t.Setenv("TEST_LOG_ROTATE_COMPRESS", "1")
+	t.Setenv("TEST_LOG_REDACT_KEYS", "password,*token*")
+	t.Setenv("TEST_LOG_SAMPLING_FIRST", "10")
+	t.Setenv("TEST_LOG_SAMPLING_TICK", "2s")
+
+	cfg, err := logger.ConfigFromEnv("TEST_LOG_")
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg, qt.DeepEquals, logger.Config{
+		Level:  logger.Level(-4),
+		Format: logger.FormatLogrusJSON,
+		Outputs: []logger.OutputConfig{
+			{Type: logger.OutputStdout},
+			{Type: logger.OutputRotating, Path: "/var/log/app.log", RotateEvery: logger.Duration(time.Hour), Compress: true},
+		},
+		AddSource: true,
+		Redact:    &logger.RedactConfig{Keys: []string{"password", "*token*"}},
+		Sampling:  &logger.SamplingConfig{Tick: logger.Duration(2 * time.Second), First: 10},
+	})
+
+	t.Setenv("TEST_LOG_LEVEL", "loud")
+	t.Setenv("TEST_LOG_ADD_SOURCE", "maybe")
+	_, err = logger.ConfigFromEnv("TEST_LOG_")
+	c.Assert(err, qt.ErrorMatches, `logger: env var TEST_LOG_LEVEL: logger: level string "loud": unknown name
+logger: env var TEST_LOG_ADD_SOURCE: strconv.ParseBool: parsing "maybe": invalid syntax`)
+}
+
+func TestFromConfig_Console(t *testing.T) {
+	c := qt.New(t)
+
+	name := filepath.Join(t.TempDir(), "app.log")
+	sl, closer, err := logger.FromConfigWithCloser(logger.Config{
+		Format:     logger.FormatConsole,
+		Outputs:    []logger.OutputConfig{{Type: logger.OutputFile, Path: name}},
+		TimeFormat: "2006",
+	})
+	c.Assert(err, qt.IsNil)
+	sl.Info("shown")
+	c.Assert(closer.Close(), qt.IsNil)
+
+	b, err := os.ReadFile(name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, time.Now().Format("2006")+" INFO  shown\n")
+}
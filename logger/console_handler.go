@@ -0,0 +1,322 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ANSI escape sequences used by ConsoleHandler.
+const (
+	ansiReset      = "\x1b[0m"
+	ansiFaint      = "\x1b[2m"
+	ansiRed        = "\x1b[31m"
+	ansiMagenta    = "\x1b[35m"
+	ansiCyan       = "\x1b[36m"
+	ansiGray       = "\x1b[90m"
+	ansiBoldRed    = "\x1b[1;31m"
+	ansiBoldYellow = "\x1b[1;33m"
+	ansiBoldCyan   = "\x1b[1;36m"
+	ansiRedBadge   = "\x1b[1;97;41m"
+)
+
+// consoleMessageWidth is the width the messages are padded to when followed by attributes,
+// so that the attributes of consecutive records line up, like in the colored output of logrus.
+const consoleMessageWidth = 40
+
+// ConsoleHandlerOptions are options for ConsoleHandler.
+type ConsoleHandlerOptions struct {
+	// Level reports the minimum record level that will be logged. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+
+	// AddSource adds the file and line of the logging calls to the records.
+	AddSource bool
+
+	// TimeFormat is the layout of the timestamps. Defaults to "15:04:05.000".
+	TimeFormat string
+
+	// RelativeTime makes the timestamps the time elapsed since Start, e.g. "+12.345s",
+	// instead of the time of day.
+	RelativeTime bool
+
+	// Start is the reference of the relative timestamps. Defaults to the creation of the handler.
+	Start time.Time
+
+	// NoColor disables the colors. By default, they are enabled if the output is a terminal
+	// and the NO_COLOR environment variable is empty (see https://no-color.org).
+	NoColor bool
+
+	// ForceColor enables the colors even if the output is not a terminal.
+	ForceColor bool
+}
+
+var _ slog.Handler = (*ConsoleHandler)(nil)
+
+// ConsoleHandler is a slog.Handler that writes human-friendly, colored records for development:
+//
+//	15:04:05.000 INFO  request served                           method=GET status=200
+//	15:04:05.012 ERROR query failed                             error="query: timeout"
+//	  error.stack:
+//	    main.run /app/main.go:42
+//	  request:
+//	    id: 42
+//	    user:
+//	      name: bob
+//
+// Level badges are aligned and include the TRACE, FATAL and PANIC levels. Scalar attributes
+// are written on the line of the record; groups (including those opened with WithGroup)
+// and the stack traces of the errors attached with WithError are written below it.
+// Errors are written as their message, regardless of the ErrorFormatter.
+type ConsoleHandler struct {
+	opts   ConsoleHandlerOptions
+	color  bool
+	mu     *sync.Mutex
+	w      io.Writer
+	frames []consoleFrame
+}
+
+// consoleFrame holds the attributes added with WithAttrs within a group opened with WithGroup.
+// The first frame is the top level.
+type consoleFrame struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewConsoleHandler returns a ConsoleHandler that writes to w. If opts is nil, the default options are used.
+func NewConsoleHandler(w io.Writer, opts *ConsoleHandlerOptions) *ConsoleHandler {
+	h := &ConsoleHandler{
+		mu:     &sync.Mutex{},
+		w:      w,
+		frames: []consoleFrame{{}},
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.TimeFormat == "" {
+		h.opts.TimeFormat = "15:04:05.000"
+	}
+	if h.opts.Start.IsZero() {
+		h.opts.Start = time.Now()
+	}
+	h.color = h.opts.ForceColor || (!h.opts.NoColor && os.Getenv("NO_COLOR") == "" && isTerminal(w))
+	return h
+}
+
+// isTerminal reports whether w is a terminal (a character device).
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// WithAttrs returns a new ConsoleHandler that writes the given attributes with every record.
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	r := *h
+	r.frames = slices.Clone(h.frames)
+	last := &r.frames[len(r.frames)-1]
+	last.attrs = append(slices.Clip(last.attrs), attrs...)
+	return &r
+}
+
+// WithGroup returns a new ConsoleHandler that writes the subsequent attributes in the group name.
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	r := *h
+	r.frames = append(slices.Clip(h.frames), consoleFrame{group: name})
+	return &r
+}
+
+// Handle writes the record.
+func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var inline, blocks bytes.Buffer
+	for _, a := range h.attrs(r) {
+		h.appendAttr(&inline, &blocks, a)
+	}
+
+	var b bytes.Buffer
+	if !r.Time.IsZero() {
+		h.paint(&b, ansiFaint, h.timestamp(r.Time))
+		b.WriteByte(' ')
+	}
+	h.paint(&b, levelColor(r.Level), fmt.Sprintf("%-5s", Level(r.Level).String()))
+	b.WriteByte(' ')
+	b.WriteString(r.Message)
+	if h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		b.WriteByte(' ')
+		h.paint(&b, ansiFaint, fmt.Sprintf("(%s:%d)", filepath.Join(filepath.Base(filepath.Dir(f.File)), filepath.Base(f.File)), f.Line))
+	}
+	if inline.Len() > 0 {
+		if n := len(r.Message); n < consoleMessageWidth {
+			b.WriteString(strings.Repeat(" ", consoleMessageWidth-n))
+		}
+		b.Write(inline.Bytes())
+	}
+	b.WriteByte('\n')
+	b.Write(blocks.Bytes())
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(b.Bytes())
+	return err
+}
+
+// attrs returns the top-level attributes of the record, with the groups opened
+// with WithGroup turned into group attributes.
+func (h *ConsoleHandler) attrs(r slog.Record) []slog.Attr {
+	last := len(h.frames) - 1
+	attrs := make([]slog.Attr, 0, len(h.frames[last].attrs)+r.NumAttrs())
+	attrs = append(attrs, h.frames[last].attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for i := last; i > 0; i-- {
+		group := slog.Attr{Key: h.frames[i].group, Value: slog.GroupValue(attrs...)}
+		attrs = append(slices.Clip(h.frames[i-1].attrs), group)
+	}
+	return attrs
+}
+
+func (h *ConsoleHandler) timestamp(t time.Time) string {
+	if h.opts.RelativeTime {
+		return fmt.Sprintf("%+9.3fs", t.Sub(h.opts.Start).Seconds())
+	}
+	return t.Format(h.opts.TimeFormat)
+}
+
+// appendAttr writes a top-level attribute: scalars to inline, groups and stack traces to blocks.
+func (h *ConsoleHandler) appendAttr(inline, blocks *bytes.Buffer, a slog.Attr) {
+	if ev, ok := asErrorValue(a.Value); ok {
+		h.appendInline(inline, a.Key, ev.Error())
+		if frames := ev.Frames(); len(frames) > 0 {
+			h.paint(blocks, ansiRed, "  "+a.Key+".stack:")
+			blocks.WriteByte('\n')
+			for _, f := range frames {
+				fmt.Fprintf(blocks, "    %s %s:%d\n", f.Function, f.File, f.Line)
+			}
+		}
+		return
+	}
+
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		h.appendInline(inline, a.Key, a.Value.String())
+		return
+	}
+	if a.Key == "" {
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(inline, blocks, ga)
+		}
+		return
+	}
+	h.appendBlock(blocks, a, 1)
+}
+
+func (h *ConsoleHandler) appendInline(b *bytes.Buffer, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	h.paint(b, ansiCyan, key)
+	b.WriteByte('=')
+	b.WriteString(consoleQuote(value))
+}
+
+// appendBlock writes the group a as an indented block, one attribute per line.
+func (h *ConsoleHandler) appendBlock(b *bytes.Buffer, a slog.Attr, depth int) {
+	group := a.Value.Group()
+	if len(group) == 0 {
+		return
+	}
+	indent := strings.Repeat("  ", depth)
+	b.WriteString(indent)
+	h.paint(b, ansiCyan, a.Key+":")
+	b.WriteByte('\n')
+	for _, ga := range group {
+		if ev, ok := asErrorValue(ga.Value); ok {
+			ga = slog.String(ga.Key, ev.Error())
+		}
+		ga.Value = ga.Value.Resolve()
+		if ga.Equal(slog.Attr{}) {
+			continue
+		}
+		if ga.Value.Kind() == slog.KindGroup {
+			h.appendBlock(b, ga, depth+1)
+			continue
+		}
+		b.WriteString(indent + "  ")
+		h.paint(b, ansiCyan, ga.Key+":")
+		b.WriteByte(' ')
+		b.WriteString(consoleQuote(ga.Value.String()))
+		b.WriteByte('\n')
+	}
+}
+
+// paint writes s in the given color, if colors are enabled.
+func (h *ConsoleHandler) paint(b *bytes.Buffer, color, s string) {
+	if !h.color {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(color)
+	b.WriteString(s)
+	b.WriteString(ansiReset)
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level < slog.LevelDebug:
+		return ansiGray
+	case level < slog.LevelInfo:
+		return ansiMagenta
+	case level < slog.LevelWarn:
+		return ansiBoldCyan
+	case level < slog.LevelError:
+		return ansiBoldYellow
+	case level < SlogLevelFatal:
+		return ansiBoldRed
+	default:
+		return ansiRedBadge
+	}
+}
+
+// consoleQuote quotes s if it is empty or needs quoting according to the rules of logrus.
+func consoleQuote(s string) string {
+	if s == "" || needsQuoting(s) {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
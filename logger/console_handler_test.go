@@ -0,0 +1,99 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func TestConsoleHandler(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	l := slog.New(logger.NewConsoleHandler(&b, &logger.ConsoleHandlerOptions{Level: logger.SlogLevelTrace}))
+
+	for _, level := range []slog.Level{logger.SlogLevelTrace, slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError, logger.SlogLevelFatal} {
+		l.Log(context.Background(), level, "msg")
+	}
+	l.With("a", 1).WithGroup("req").With("id", 42).Info("grouped", slog.Group("user", "name", "bob"), "empty", "")
+	l.Info("inline", slog.Group("", "k", "with space"))
+
+	lines := strings.Split(b.String(), "\n")
+	for i, level := range []string{"TRACE", "DEBUG", "INFO ", "WARN ", "ERROR", "FATAL"} {
+		c.Assert(lines[i], qt.Matches, `\d\d:\d\d:\d\d\.\d\d\d `+level+` msg`)
+	}
+	c.Assert(strings.Join(lines[6:], "\n"), qt.Matches, fmt.Sprintf(`\S+ INFO  %-40sa=1
+  req:
+    id: 42
+    user:
+      name: bob
+    empty: ""
+\S+ INFO  %-40sk="with space"
+`, "grouped", "inline"))
+}
+
+func TestConsoleHandler_Options(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	h := logger.NewConsoleHandler(&b, &logger.ConsoleHandlerOptions{
+		AddSource:    true,
+		RelativeTime: true,
+		Start:        testTime.Add(-1500 * time.Millisecond),
+		ForceColor:   true,
+	})
+	sl := logger.NewSlog(slog.New(h))
+	sl.WithField("k", "v").Warn("colored")
+	_, file, line, _ := runtime.Caller(0)
+
+	source := fmt.Sprintf("(%s:%d)", filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file)), line-1)
+	c.Assert(b.String(), qt.Contains, "\x1b[1;33mWARN \x1b[0m colored \x1b[2m"+source+"\x1b[0m")
+	c.Assert(b.String(), qt.Contains, "\x1b[36mk\x1b[0m=v\n")
+
+	b.Reset()
+	r := slog.NewRecord(testTime, logger.SlogLevelPanic, "relative", 0)
+	c.Assert(h.Handle(context.Background(), r), qt.IsNil)
+	c.Assert(b.String(), qt.Equals, "\x1b[2m   +1.500s\x1b[0m \x1b[1;97;41mPANIC\x1b[0m relative\n")
+}
+
+func TestConsoleHandler_ErrorStack(t *testing.T) {
+	c := qt.New(t)
+
+	logger.SetErrorOptions(logger.ErrorOptions{Stack: true, MaxStackDepth: 1})
+	defer logger.SetErrorOptions(logger.ErrorOptions{})
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(logger.NewConsoleHandler(&b, &logger.ConsoleHandlerOptions{NoColor: true})))
+	sl.WithError(errors.New("boom")).Error("failed")
+
+	c.Assert(b.String(), qt.Matches, fmt.Sprintf(`\S+ ERROR %-40serror=boom
+  error.stack:
+    .*TestConsoleHandler_ErrorStack .*console_handler_test.go:\d+
+`, "failed"))
+}
+
+func TestConsoleHandler_NoTerminal(t *testing.T) {
+	c := qt.New(t)
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	c.Assert(err, qt.IsNil)
+	defer f.Close()
+
+	l := slog.New(logger.NewConsoleHandler(f, nil))
+	l.Info("plain", "k", "v")
+	b, err := os.ReadFile(f.Name())
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Not(qt.Contains), "\x1b[")
+}
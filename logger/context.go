@@ -0,0 +1,29 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+)
+
+type contextKey struct{}
+
+// IntoContext returns a copy of ctx that carries the logger l.
+// Use FromContext to retrieve it.
+func IntoContext(ctx context.Context, l *Slog) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx by IntoContext.
+// If ctx does not carry a logger, a logger wrapping slog.Default() is returned.
+// In both cases the returned logger is bound to ctx (see Slog.WithContext),
+// so handlers receive ctx with every record.
+func FromContext(ctx context.Context) *Slog {
+	l, ok := ctx.Value(contextKey{}).(*Slog)
+	if !ok || l == nil {
+		l = std()
+	}
+	return l.WithContext(ctx)
+}
@@ -0,0 +1,88 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ContextKey describes a context value that ContextHandler adds to every record.
+type ContextKey struct {
+	// Key is the key the value was stored under with context.WithValue.
+	Key any
+	// Attr is the name of the attribute the value is logged as.
+	Attr string
+}
+
+var (
+	_ slog.Handler = (*ContextHandler)(nil)
+	_ Flusher      = (*ContextHandler)(nil)
+)
+
+// ContextHandler is a slog.Handler wrapper that extracts the configured keys
+// from the context passed to Handle and adds them to the record as attributes.
+// Keys that are missing from the context are skipped.
+//
+// Example usage:
+//
+//	h := logger.NewContextHandler(slog.NewJSONHandler(os.Stderr, nil),
+//		logger.ContextKey{Key: traceIDKey{}, Attr: "trace_id"},
+//	)
+//	l := logger.NewSlog(slog.New(h))
+//	l.WithContext(ctx).Info("handling request") // ... "trace_id":"..."
+type ContextHandler struct {
+	next slog.Handler
+	keys []ContextKey
+}
+
+// NewContextHandler returns a new ContextHandler that wraps next and extracts keys from the context.
+func NewContextHandler(next slog.Handler, keys ...ContextKey) *ContextHandler {
+	return &ContextHandler{
+		next: next,
+		keys: keys,
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds the configured context values to r and passes it to the wrapped handler.
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, len(h.keys))
+	for _, k := range h.keys {
+		if v := ctx.Value(k.Key); v != nil {
+			attrs = append(attrs, slog.Any(k.Attr, v))
+		}
+	}
+	if len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a new ContextHandler whose wrapped handler has the given attributes.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{
+		next: h.next.WithAttrs(attrs),
+		keys: h.keys,
+	}
+}
+
+// WithGroup returns a new ContextHandler whose wrapped handler has the given group.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{
+		next: h.next.WithGroup(name),
+		keys: h.keys,
+	}
+}
+
+// Flush flushes the wrapped handler if it implements Flusher.
+func (h *ContextHandler) Flush(ctx context.Context) error {
+	return flushHandler(ctx, h.next)
+}
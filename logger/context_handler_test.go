@@ -0,0 +1,38 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func TestContextHandler(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	h := logger.NewContextHandler(
+		slog.NewTextHandler(&b, &slog.HandlerOptions{
+			ReplaceAttr: func(_groups []string, a slog.Attr) slog.Attr {
+				if a.Key == "time" {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}),
+		logger.ContextKey{Key: ctxKey("trace"), Attr: "trace_id"},
+		logger.ContextKey{Key: ctxKey("missing"), Attr: "missing"},
+	)
+
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "abc")
+	sl := logger.NewSlog(slog.New(h))
+	sl.WithField("k", "v").WithContext(ctx).Info("test message")
+	sl.Info("no trace")
+
+	c.Assert(b.String(), qt.Equals, "level=INFO msg=\"test message\" k=v trace_id=abc\n"+
+		"level=INFO msg=\"no trace\"\n")
+}
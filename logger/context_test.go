@@ -0,0 +1,65 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+type ctxKey string
+
+// ctxHandler records the contexts passed to Handle.
+type ctxHandler struct {
+	slog.Handler
+	contexts *[]context.Context
+}
+
+func (h ctxHandler) Handle(ctx context.Context, r slog.Record) error {
+	*h.contexts = append(*h.contexts, ctx)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h ctxHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ctxHandler{Handler: h.Handler.WithAttrs(attrs), contexts: h.contexts}
+}
+
+func TestSlog_WithContext(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	var contexts []context.Context
+	h := ctxHandler{Handler: slog.NewTextHandler(&b, nil), contexts: &contexts}
+
+	ctx := context.WithValue(context.Background(), ctxKey("id"), "42")
+	sl := logger.NewSlog(slog.New(h))
+	sl.WithContext(ctx).WithField("k", "v").Infof("test %d", 1)
+	sl.Info("no context")
+
+	c.Assert(contexts, qt.HasLen, 2)
+	c.Assert(contexts[0].Value(ctxKey("id")), qt.Equals, "42")
+	c.Assert(contexts[1], qt.Equals, context.Background())
+}
+
+func TestFromContext(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(&b, nil)))
+	ctx := logger.IntoContext(context.Background(), sl.WithField("k", "v"))
+
+	logger.FromContext(ctx).Info("test message")
+	c.Assert(b.String(), qt.Contains, "msg=\"test message\" k=v\n")
+}
+
+func TestFromContext_Default(t *testing.T) {
+	c := qt.New(t)
+
+	l := logger.FromContext(context.Background())
+	c.Assert(l, qt.IsNotNil)
+	c.Assert(l.Logger, qt.Equals, slog.Default())
+}
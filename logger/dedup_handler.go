@@ -0,0 +1,339 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+)
+
+// DedupStrategy defines how DedupHandler resolves attributes with the same key.
+type DedupStrategy int
+
+const (
+	// DedupLastWins keeps the value of the last attribute, at the position of the first one,
+	// like logrus does when a field is set twice.
+	DedupLastWins DedupStrategy = iota
+
+	// DedupFirstWins keeps the first attribute and drops the others.
+	DedupFirstWins
+
+	// DedupRename keeps all the attributes, renaming the duplicates by appending
+	// DedupOptions.Suffix and their number to their key: user, user#1, user#2, etc.
+	DedupRename
+)
+
+// DedupOptions are options for DedupHandler.
+type DedupOptions struct {
+	// Strategy is the way duplicate keys are resolved. Defaults to DedupLastWins.
+	Strategy DedupStrategy
+
+	// Suffix separates the key of a renamed attribute from its number with DedupRename. Defaults to "#".
+	Suffix string
+}
+
+// dedupIndexThreshold is the number of attributes above which the keys are looked up in a map
+// rather than by a linear search, and above which records are never passed as is.
+const dedupIndexThreshold = 16
+
+var (
+	_ slog.Handler = (*DedupHandler)(nil)
+	_ Flusher      = (*DedupHandler)(nil)
+)
+
+// DedupHandler is a slog.Handler wrapper that resolves the attributes with duplicate keys,
+// such as the ones produced by Slog.WithField("user", a).WithField("user", b), which most
+// handlers write as is, producing JSON objects that many parsers reject.
+//
+// Duplicates are resolved within every group, including the groups opened with WithGroup,
+// and groups with the same key are merged rather than resolved. Attributes of groups with
+// an empty key are inlined, like slog handlers do, and empty groups are dropped.
+// Values implementing slog.LogValuer are not resolved, so that the wrapped handler
+// renders them as usual (see ErrorValue): the groups they resolve to are left as is.
+//
+// The handler keeps track of the attributes added with WithAttrs and WithGroup, so that
+// the duplicates added by later calls and by the records can be resolved. As long as
+// there is nothing to resolve, the records are passed as is to the wrapped handler
+// with the attributes and groups applied, so the overhead is small.
+type DedupHandler struct {
+	next   slog.Handler
+	opts   DedupOptions
+	frames []dedupFrame
+
+	// prepared is next with the attributes and groups of the frames applied, or nil
+	// if a group has the key of an attribute of its parent.
+	prepared slog.Handler
+}
+
+// dedupFrame holds the attributes added with WithAttrs within a group opened with WithGroup,
+// with the duplicates resolved. The first frame is the top level.
+type dedupFrame struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewDedupHandler returns a DedupHandler wrapping next.
+func NewDedupHandler(next slog.Handler, opts DedupOptions) *DedupHandler {
+	if opts.Suffix == "" {
+		opts.Suffix = "#"
+	}
+	return &DedupHandler{
+		next:     next,
+		opts:     opts,
+		frames:   []dedupFrame{{}},
+		prepared: next,
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *DedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler with the duplicate keys resolved.
+func (h *DedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.prepared != nil && h.unique(r) {
+		return h.prepared.Handle(ctx, r)
+	}
+
+	last := len(h.frames) - 1
+	attrs := make([]slog.Attr, 0, len(h.frames[last].attrs)+r.NumAttrs())
+	attrs = append(attrs, h.frames[last].attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	attrs = h.dedup(attrs)
+	for i := last; i > 0; i-- {
+		parent := h.frames[i-1].attrs
+		if len(attrs) == 0 {
+			attrs = parent
+			continue
+		}
+		group := slog.Attr{Key: h.frames[i].group, Value: slog.GroupValue(attrs...)}
+		attrs = h.dedup(append(slices.Clip(parent), group))
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(attrs...)
+	return h.next.Handle(ctx, nr)
+}
+
+// unique reports whether the attributes of r need no resolution, neither among themselves
+// nor with the attributes of the innermost frame.
+func (h *DedupHandler) unique(r slog.Record) bool {
+	if r.NumAttrs() > dedupIndexThreshold {
+		return false
+	}
+	frame := h.frames[len(h.frames)-1].attrs
+	var keys [dedupIndexThreshold]string
+	n := 0
+	unique := true
+	r.Attrs(func(a slog.Attr) bool {
+		unique = a.Key != "" &&
+			(a.Value.Kind() != slog.KindGroup || uniqueAttrs(a.Value.Group())) &&
+			!slices.Contains(keys[:n], a.Key) &&
+			!hasKey(frame, a.Key)
+		keys[n] = a.Key
+		n++
+		return unique
+	})
+	return unique
+}
+
+// WithAttrs returns a new DedupHandler that adds the given attributes to every record.
+func (h *DedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	r := *h
+	r.frames = slices.Clone(h.frames)
+	last := &r.frames[len(r.frames)-1]
+	last.attrs = slices.Concat(last.attrs, attrs)
+	if uniqueAttrs(last.attrs) {
+		if h.prepared != nil {
+			r.prepared = h.prepared.WithAttrs(attrs)
+		}
+		return &r
+	}
+	last.attrs = h.dedup(last.attrs)
+	r.prepared = r.prepare()
+	return &r
+}
+
+// WithGroup returns a new DedupHandler that nests the subsequent attributes in the group name.
+func (h *DedupHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	r := *h
+	r.frames = append(slices.Clip(h.frames), dedupFrame{group: name})
+	r.prepared = nil
+	if h.prepared != nil && !hasKey(h.frames[len(h.frames)-1].attrs, name) {
+		r.prepared = h.prepared.WithGroup(name)
+	}
+	return &r
+}
+
+// prepare returns the wrapped handler with the attributes and groups of the frames applied,
+// or nil if a group has the key of an attribute of its parent.
+func (h *DedupHandler) prepare() slog.Handler {
+	next := h.next
+	for i, f := range h.frames {
+		if i > 0 {
+			if hasKey(h.frames[i-1].attrs, f.group) {
+				return nil
+			}
+			next = next.WithGroup(f.group)
+		}
+		if len(f.attrs) > 0 {
+			next = next.WithAttrs(f.attrs)
+		}
+	}
+	return next
+}
+
+// Flush flushes the wrapped handler.
+func (h *DedupHandler) Flush(ctx context.Context) error {
+	return flushHandler(ctx, h.next)
+}
+
+// dedup returns attrs with the duplicate keys resolved, recursively. attrs are returned as is
+// if there is nothing to resolve.
+func (h *DedupHandler) dedup(attrs []slog.Attr) []slog.Attr {
+	if uniqueAttrs(attrs) {
+		return attrs
+	}
+	d := deduper{opts: &h.opts, attrs: make([]slog.Attr, 0, len(attrs))}
+	d.addAll(attrs)
+	return d.attrs
+}
+
+// uniqueAttrs reports whether attrs have neither duplicate keys nor attributes to inline, recursively.
+func uniqueAttrs(attrs []slog.Attr) bool {
+	var seen map[string]bool
+	if len(attrs) > dedupIndexThreshold {
+		seen = make(map[string]bool, len(attrs))
+	}
+	for i, a := range attrs {
+		if a.Key == "" || (a.Value.Kind() == slog.KindGroup && !uniqueAttrs(a.Value.Group())) {
+			return false
+		}
+		if seen == nil {
+			for _, prev := range attrs[:i] {
+				if prev.Key == a.Key {
+					return false
+				}
+			}
+			continue
+		}
+		if seen[a.Key] {
+			return false
+		}
+		seen[a.Key] = true
+	}
+	return true
+}
+
+// deduper builds a list of attributes with unique keys.
+type deduper struct {
+	opts  *DedupOptions
+	attrs []slog.Attr
+	index map[string]int // the positions of the keys in attrs, once there are many of them
+}
+
+func (d *deduper) addAll(attrs []slog.Attr) {
+	for _, a := range attrs {
+		d.add(a)
+	}
+}
+
+func (d *deduper) add(a slog.Attr) {
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key == "" {
+			d.addAll(a.Value.Group())
+			return
+		}
+		group := d.sub(a.Value.Group())
+		if len(group) == 0 {
+			return
+		}
+		a.Value = slog.GroupValue(group...)
+	} else if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	i := d.find(a.Key)
+	if i < 0 {
+		d.append(a)
+		return
+	}
+	prev := &d.attrs[i]
+	if prev.Value.Kind() == slog.KindGroup && a.Value.Kind() == slog.KindGroup {
+		prev.Value = slog.GroupValue(d.sub(slices.Concat(prev.Value.Group(), a.Value.Group()))...)
+		return
+	}
+	switch d.opts.Strategy {
+	case DedupFirstWins:
+	case DedupRename:
+		for n := 1; ; n++ {
+			key := a.Key + d.opts.Suffix + strconv.Itoa(n)
+			if d.find(key) < 0 {
+				a.Key = key
+				d.append(a)
+				return
+			}
+		}
+	default:
+		prev.Value = a.Value
+	}
+}
+
+// sub returns the attributes of a group with the duplicate keys resolved.
+func (d *deduper) sub(attrs []slog.Attr) []slog.Attr {
+	if uniqueAttrs(attrs) {
+		return attrs
+	}
+	sub := deduper{opts: d.opts, attrs: make([]slog.Attr, 0, len(attrs))}
+	sub.addAll(attrs)
+	return sub.attrs
+}
+
+// find returns the position of the attribute with the given key, or -1.
+func (d *deduper) find(key string) int {
+	if d.index != nil {
+		if i, ok := d.index[key]; ok {
+			return i
+		}
+		return -1
+	}
+	for i, a := range d.attrs {
+		if a.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasKey reports whether attrs have an attribute with the given key.
+func hasKey(attrs []slog.Attr, key string) bool {
+	return slices.ContainsFunc(attrs, func(a slog.Attr) bool { return a.Key == key })
+}
+
+func (d *deduper) append(a slog.Attr) {
+	d.attrs = append(d.attrs, a)
+	if d.index == nil && len(d.attrs) > dedupIndexThreshold {
+		d.index = make(map[string]int, 2*len(d.attrs))
+		for i, a := range d.attrs {
+			d.index[a.Key] = i
+		}
+		return
+	}
+	if d.index != nil {
+		d.index[a.Key] = len(d.attrs) - 1
+	}
+}
@@ -0,0 +1,153 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func newDedupLogger(b *bytes.Buffer, opts logger.DedupOptions) *slog.Logger {
+	return slog.New(logger.NewDedupHandler(slog.NewJSONHandler(b, &slog.HandlerOptions{ReplaceAttr: dropTime}), opts))
+}
+
+func TestDedupHandler_Strategies(t *testing.T) {
+	tests := []struct {
+		name string
+		opts logger.DedupOptions
+		want string
+	}{
+		{
+			name: "last wins",
+			want: `{"level":"INFO","msg":"m","user":"carol","id":1}`,
+		},
+		{
+			name: "first wins",
+			opts: logger.DedupOptions{Strategy: logger.DedupFirstWins},
+			want: `{"level":"INFO","msg":"m","user":"alice","id":1}`,
+		},
+		{
+			name: "rename",
+			opts: logger.DedupOptions{Strategy: logger.DedupRename},
+			want: `{"level":"INFO","msg":"m","user":"alice","user#1":"bob","id":1,"user#2":"carol"}`,
+		},
+		{
+			name: "rename with suffix",
+			opts: logger.DedupOptions{Strategy: logger.DedupRename, Suffix: "_"},
+			want: `{"level":"INFO","msg":"m","user":"alice","user_1":"bob","id":1,"user_2":"carol"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			var b bytes.Buffer
+			sl := logger.NewSlog(newDedupLogger(&b, tt.opts))
+			sl.WithField("user", "alice").WithField("user", "bob").WithField("id", 1).Logger.Info("m", "user", "carol")
+
+			c.Assert(b.String(), qt.Equals, tt.want+"\n")
+		})
+	}
+}
+
+func TestDedupHandler_RenameCollision(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	l := newDedupLogger(&b, logger.DedupOptions{Strategy: logger.DedupRename})
+	l.Info("m", "k", 1, "k#1", 2, "k", 3)
+
+	c.Assert(b.String(), qt.Equals, `{"level":"INFO","msg":"m","k":1,"k#1":2,"k#2":3}`+"\n")
+}
+
+func TestDedupHandler_Groups(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	l := newDedupLogger(&b, logger.DedupOptions{})
+
+	l.With("id", 1).WithGroup("req").With("id", 2).Info("m", "id", 3)
+	l.Info("m", slog.Group("g", "a", 1, "b", 2), slog.Group("g", "b", 3, "c", 4))
+	l.With("req", "x").WithGroup("req").Info("m", "id", 1)
+	l.With("req", "x").WithGroup("req").Info("m")
+	l.Info("m", "a", 1, slog.Group("", "a", 2, "b", 3), slog.Group("empty"))
+	l.WithGroup("req").With("g", slog.GroupValue(slog.Int("a", 1))).Info("m", slog.Group("g", "a", 2))
+
+	c.Assert(b.String(), qt.Equals, `{"level":"INFO","msg":"m","id":1,"req":{"id":3}}
+{"level":"INFO","msg":"m","g":{"a":1,"b":3,"c":4}}
+{"level":"INFO","msg":"m","req":{"id":1}}
+{"level":"INFO","msg":"m","req":"x"}
+{"level":"INFO","msg":"m","a":2,"b":3}
+{"level":"INFO","msg":"m","req":{"g":{"a":2}}}
+`)
+}
+
+func TestDedupHandler_PassThrough(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	l := newDedupLogger(&b, logger.DedupOptions{})
+
+	l.With("a", 1).WithGroup("g").With("b", 2).Info("m", "c", 3, slog.Group("d", "e", 4))
+	l.Info("m")
+
+	c.Assert(b.String(), qt.Equals, `{"level":"INFO","msg":"m","a":1,"g":{"b":2,"c":3,"d":{"e":4}}}
+{"level":"INFO","msg":"m"}
+`)
+}
+
+func TestDedupHandler_PassThroughDoesNotAllocate(t *testing.T) {
+	c := qt.New(t)
+
+	plain := slog.New(slog.NewJSONHandler(io.Discard, nil)).With("a", 1).WithGroup("g")
+	dedup := slog.New(logger.NewDedupHandler(slog.NewJSONHandler(io.Discard, nil), logger.DedupOptions{})).With("a", 1).WithGroup("g")
+
+	want := testing.AllocsPerRun(100, func() { plain.Info("m", "b", 2, "c", 3) })
+	got := testing.AllocsPerRun(100, func() { dedup.Info("m", "b", 2, "c", 3) })
+	c.Assert(got, qt.Equals, want)
+}
+
+func TestDedupHandler_ManyAttrs(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	l := newDedupLogger(&b, logger.DedupOptions{Strategy: logger.DedupFirstWins})
+
+	var args []any
+	var want strings.Builder
+	for i := range 20 {
+		args = append(args, fmt.Sprintf("k%d", i), i)
+		fmt.Fprintf(&want, `,"k%d":%d`, i, i)
+	}
+	l.Info("m", append(args, "k0", 100, "k19", 100)...)
+
+	c.Assert(b.String(), qt.Equals, `{"level":"INFO","msg":"m"`+want.String()+"}\n")
+}
+
+func TestDedupHandler_Error(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	h := logger.NewDedupHandler(logger.NewLogrusTextHandler(&b, &logger.LogrusHandlerOptions{DisableTimestamp: true}), logger.DedupOptions{})
+	sl := logger.NewSlog(slog.New(h))
+	sl.WithError(errors.New("first")).WithError(errors.New("second")).Error("failed")
+
+	c.Assert(b.String(), qt.Equals, "level=error msg=failed error=second\n")
+}
+
+func TestDedupHandler_Flush(t *testing.T) {
+	c := qt.New(t)
+
+	flushed := 0
+	h := logger.NewDedupHandler(flushHandler{Handler: slog.NewTextHandler(&bytes.Buffer{}, nil), flushed: &flushed}, logger.DedupOptions{})
+	c.Assert(h.WithAttrs([]slog.Attr{slog.Int("a", 1)}).(logger.Flusher).Flush(context.Background()), qt.IsNil)
+	c.Assert(flushed, qt.Equals, 1)
+}
@@ -0,0 +1,220 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// ErrorFormatter renders the errors attached with WithError.
+type ErrorFormatter func(v *ErrorValue) slog.Value
+
+// ErrorOptions are options for the errors attached with WithError (see SetErrorOptions).
+type ErrorOptions struct {
+	// Stack makes WithError capture the stack trace of its caller.
+	Stack bool
+
+	// MaxStackDepth is the maximum number of frames of the captured stack traces. Defaults to 32.
+	MaxStackDepth int
+
+	// Formatter renders the errors. Defaults to FormatError.
+	Formatter ErrorFormatter
+}
+
+// defaultMaxStackDepth is the default of ErrorOptions.MaxStackDepth.
+const defaultMaxStackDepth = 32
+
+var (
+	// errorOptions holds the options set by SetErrorOptions, or nil for the default ones.
+	errorOptions atomic.Pointer[ErrorOptions]
+
+	defaultErrorOptions = ErrorOptions{MaxStackDepth: defaultMaxStackDepth}
+)
+
+// SetErrorOptions sets the options for the errors attached with WithError from now on.
+func SetErrorOptions(opts ErrorOptions) {
+	if opts.MaxStackDepth <= 0 {
+		opts.MaxStackDepth = defaultMaxStackDepth
+	}
+	errorOptions.Store(&opts)
+}
+
+// loadErrorOptions returns the options set by SetErrorOptions, or the default ones.
+func loadErrorOptions() *ErrorOptions {
+	if opts := errorOptions.Load(); opts != nil {
+		return opts
+	}
+	return &defaultErrorOptions
+}
+
+var (
+	_ slog.LogValuer = (*ErrorValue)(nil)
+	_ error          = (*ErrorValue)(nil)
+)
+
+// ErrorValue is the value of the "error" attribute added by WithError, as a pointer.
+// It renders the error with the ErrorFormatter set by SetErrorOptions, which defaults to FormatError.
+// It implements error and unwraps to the original error, so code expecting
+// an error value, such as hooks, keeps working.
+//
+// The logrus-compatible handlers and the legacy loggers render the original error instead,
+// like logrus does.
+type ErrorValue struct {
+	Err error
+
+	// Stack holds the program counters of the caller of WithError if ErrorOptions.Stack is set.
+	Stack []uintptr
+}
+
+// newErrorValue returns the value of err for WithError. skip is the number of frames
+// between newErrorValue and the caller of WithError.
+func newErrorValue(err error, skip int) *ErrorValue {
+	v := &ErrorValue{Err: err}
+	opts := loadErrorOptions()
+	if opts.Stack {
+		pcs := make([]uintptr, opts.MaxStackDepth)
+		n := runtime.Callers(skip+2, pcs)
+		v.Stack = pcs[:n:n]
+	}
+	return v
+}
+
+// Error returns the message of the error.
+func (v *ErrorValue) Error() string {
+	return v.Err.Error()
+}
+
+// Unwrap returns the original error.
+func (v *ErrorValue) Unwrap() error {
+	return v.Err
+}
+
+// Frames returns the frames of the stack trace, if any.
+func (v *ErrorValue) Frames() []runtime.Frame {
+	if len(v.Stack) == 0 {
+		return nil
+	}
+	var frames []runtime.Frame
+	iter := runtime.CallersFrames(v.Stack)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			return frames
+		}
+	}
+}
+
+// LogValue implements slog.LogValuer.
+func (v *ErrorValue) LogValue() slog.Value {
+	if format := loadErrorOptions().Formatter; format != nil {
+		return format(v)
+	}
+	return FormatError(v)
+}
+
+// FormatError is the default ErrorFormatter. It renders the error as a group with the message
+// ("msg") and the type ("type") of the error, the errors it wraps ("chain"), if any, and
+// the stack trace ("stack"), if any:
+//
+//	error.msg="query: timeout" error.type=*fmt.wrapError
+//	error.chain.0.msg=timeout error.chain.0.type=*errors.errorString
+//	error.stack.0="main.run /app/main.go:42"
+//
+// The chain lists the errors returned by Unwrap() error and Unwrap() []error, depth-first.
+func FormatError(v *ErrorValue) slog.Value {
+	attrs := []slog.Attr{
+		slog.String("msg", v.Err.Error()),
+		slog.String("type", errorType(v.Err)),
+	}
+
+	var chain []slog.Attr
+	for i, err := range unwrapChain(nil, v.Err) {
+		chain = append(chain, slog.Group(strconv.Itoa(i),
+			slog.String("msg", err.Error()),
+			slog.String("type", errorType(err)),
+		))
+	}
+	if len(chain) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "chain", Value: slog.GroupValue(chain...)})
+	}
+
+	var stack []slog.Attr
+	for i, frame := range v.Frames() {
+		stack = append(stack, slog.String(strconv.Itoa(i), fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line)))
+	}
+	if len(stack) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "stack", Value: slog.GroupValue(stack...)})
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// unwrapChain appends the errors wrapped by err to chain, depth-first.
+func unwrapChain(chain []error, err error) []error {
+	for _, next := range unwrapDirect(err) {
+		chain = unwrapChain(append(chain, next), next)
+	}
+	return chain
+}
+
+// unwrapDirect returns the non-nil errors err wraps directly.
+func unwrapDirect(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := u.Unwrap(); next != nil {
+			return []error{next}
+		}
+	case interface{ Unwrap() []error }:
+		var wrapped []error
+		for _, next := range u.Unwrap() {
+			if next != nil {
+				wrapped = append(wrapped, next)
+			}
+		}
+		return wrapped
+	}
+	return nil
+}
+
+// errorType returns the type of err, or of the original error if err was redacted by RedactHandler.
+func errorType(err error) string {
+	if r, ok := err.(*redactedError); ok {
+		err = r.err
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// withError returns a copy of the logger with err attached as an ErrorValue.
+// It must be called directly by the WithError methods and functions, so that
+// the stack trace starts at their caller.
+func (s *Slog) withError(err error) *Slog {
+	if err == nil {
+		return s.with(errKey, err)
+	}
+	return s.with(errKey, newErrorValue(err, 2))
+}
+
+// asErrorValue returns the ErrorValue held by v, if any.
+func asErrorValue(v slog.Value) (*ErrorValue, bool) {
+	if v.Kind() != slog.KindLogValuer {
+		return nil, false
+	}
+	ev, ok := v.Any().(*ErrorValue)
+	return ev, ok
+}
+
+// originalError returns the error of v if it is an ErrorValue, or nil otherwise, for the handlers
+// that render errors the way logrus does.
+func originalError(v slog.Value) error {
+	if ev, ok := asErrorValue(v); ok {
+		return ev.Err
+	}
+	return nil
+}
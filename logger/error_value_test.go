@@ -0,0 +1,86 @@
+package logger_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+func TestSlog_WithError(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	sl := logger.NewSlog(slog.New(rec))
+
+	timeout := errors.New("timeout")
+	closed := errors.New("closed")
+	err := fmt.Errorf("query: %w", errors.Join(timeout, fmt.Errorf("conn: %w", closed)))
+	sl.WithError(err).Error("failed")
+
+	rec.AssertEntry(t, slog.LevelError, "failed",
+		slog.String("error.msg", err.Error()),
+		slog.String("error.type", "*fmt.wrapError"),
+		slog.String("error.chain.0.msg", "timeout\nconn: closed"),
+		slog.String("error.chain.0.type", "*errors.joinError"),
+		slog.String("error.chain.1.msg", "timeout"),
+		slog.String("error.chain.2.msg", "conn: closed"),
+		slog.String("error.chain.3.msg", "closed"),
+	)
+	_, ok := rec.Entries()[0].Attr("error.stack")
+	c.Assert(ok, qt.IsFalse)
+
+	rec.Reset()
+	sl.WithError(nil).Error("nil")
+	rec.AssertEntry(t, slog.LevelError, "nil", slog.Any("error", nil))
+}
+
+func TestSlog_WithError_Stack(t *testing.T) {
+	c := qt.New(t)
+
+	logger.SetErrorOptions(logger.ErrorOptions{Stack: true, MaxStackDepth: 2})
+	defer logger.SetErrorOptions(logger.ErrorOptions{})
+
+	rec := loggertest.New()
+	sl := logger.NewFieldsSlog(slog.New(rec))
+
+	tests := []sourceTest{
+		{"Slog", func() { sl.Slog.WithError(errors.New("boom")).Error("failed") }},
+		{"FieldsSlog", func() { sl.WithError(errors.New("boom")).Error("failed") }},
+	}
+	for _, tt := range tests {
+		c.Run(tt.name, func(c *qt.C) {
+			rec.Reset()
+			tt.fn()
+			_, line := fileLine(tt.fn)
+
+			e := rec.Entries()[0]
+			top, ok := e.Attr("error.stack.0")
+			c.Assert(ok, qt.IsTrue)
+			c.Assert(top.String(), qt.Matches, fmt.Sprintf(`.*TestSlog_WithError_Stack\.func\d+ .*/error_value_test\.go:%d`, line))
+			_, ok = e.Attr("error.stack.1")
+			c.Assert(ok, qt.IsTrue)
+			_, ok = e.Attr("error.stack.2")
+			c.Assert(ok, qt.IsFalse)
+		})
+	}
+}
+
+func TestSetErrorOptions_Formatter(t *testing.T) {
+	logger.SetErrorOptions(logger.ErrorOptions{
+		Formatter: func(v *logger.ErrorValue) slog.Value {
+			return slog.StringValue(strings.ToUpper(v.Error()))
+		},
+	})
+	defer logger.SetErrorOptions(logger.ErrorOptions{})
+
+	rec := loggertest.New()
+	logger.NewSlog(slog.New(rec)).WithError(errors.New("boom")).Warn("failed")
+	rec.AssertEntry(t, slog.LevelWarn, "failed", slog.String("error", "BOOM"))
+}
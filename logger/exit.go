@@ -0,0 +1,132 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Flusher is implemented by handlers that buffer records (e.g. asynchronous or batching handlers).
+// Fatal and Fatalf flush the handler of the logger before terminating the process,
+// so that the last records are not lost. They wait at most for the timeout set by
+// SetExitFlushTimeout, so that a blocked handler cannot prevent the process from terminating.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// DefaultExitFlushTimeout is the default maximum time Fatal and Fatalf wait for the handler
+// to be flushed (see SetExitFlushTimeout).
+const DefaultExitFlushTimeout = 5 * time.Second
+
+var (
+	exitMu           sync.Mutex
+	exitFunc         = os.Exit
+	exitHandlers     []func()
+	exitFlushTimeout = DefaultExitFlushTimeout
+)
+
+// SetExitFunc sets the function used by Exit (and thus by Fatal and Fatalf) to terminate the process.
+// Passing nil restores the default, os.Exit. It is mostly useful in tests.
+func SetExitFunc(fn func(code int)) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	if fn == nil {
+		fn = os.Exit
+	}
+	exitFunc = fn
+}
+
+// SetExitFlushTimeout sets the maximum time Fatal and Fatalf wait for the handler of the logger
+// to be flushed before running the exit handlers and terminating the process.
+// Passing zero or a negative duration restores the default, DefaultExitFlushTimeout.
+func SetExitFlushTimeout(d time.Duration) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	if d <= 0 {
+		d = DefaultExitFlushTimeout
+	}
+	exitFlushTimeout = d
+}
+
+// RegisterExitHandler registers a function to be called by Exit (and thus by Fatal and Fatalf)
+// before the process is terminated. Handlers are called in the order they were registered.
+// It mirrors logrus.RegisterExitHandler and can be used, for example, to close files or flush
+// remote sinks.
+func RegisterExitHandler(handler func()) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitHandlers = append(exitHandlers, handler)
+}
+
+// Exit runs all the registered exit handlers and then terminates the process with the given code
+// using the function set by SetExitFunc.
+func Exit(code int) {
+	runExitHandlers()
+	exitMu.Lock()
+	fn := exitFunc
+	exitMu.Unlock()
+	fn(code)
+}
+
+func runExitHandlers() {
+	exitMu.Lock()
+	handlers := make([]func(), len(exitHandlers))
+	copy(handlers, exitHandlers)
+	exitMu.Unlock()
+
+	for _, handler := range handlers {
+		runExitHandler(handler)
+	}
+}
+
+func runExitHandler(handler func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			fmt.Fprintln(os.Stderr, "logger: exit handler panicked:", err)
+		}
+	}()
+	handler()
+}
+
+// flushHandler flushes h if it implements Flusher.
+func flushHandler(ctx context.Context, h slog.Handler) error {
+	if f, ok := h.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// exit flushes the handler, waiting at most for the exit flush timeout, and terminates
+// the process using the exit function of the sink, if set, or Exit otherwise.
+func (sk sink) exit(code int) {
+	exitMu.Lock()
+	timeout := exitFlushTimeout
+	exitMu.Unlock()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(sk.ctx), timeout)
+	err := flushHandler(ctx, sk.handler)
+	cancel()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "logger: failed to flush handler:", err)
+	}
+	if sk.exitFunc == nil {
+		Exit(code)
+		return
+	}
+	runExitHandlers()
+	sk.exitFunc(code)
+}
+
+// WithExitFunc returns a copy of the logger that calls fn instead of the function set by SetExitFunc
+// to terminate the process in Fatal and Fatalf. Registered exit handlers still run before fn.
+func (s *Slog) WithExitFunc(fn func(code int)) *Slog {
+	r := s.clone()
+	r.exitFunc = fn
+	return r
+}
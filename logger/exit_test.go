@@ -0,0 +1,96 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+// flushHandler is a handler that records calls to Flush.
+type flushHandler struct {
+	slog.Handler
+	flushed *int
+}
+
+func (h flushHandler) Flush(_ context.Context) error {
+	*h.flushed++
+	return nil
+}
+
+func TestSlog_Fatal(t *testing.T) {
+	c := qt.New(t)
+
+	var handlerCalls int
+	logger.RegisterExitHandler(func() { handlerCalls++ })
+
+	var b bytes.Buffer
+	var flushed int
+	var codes []int
+	h := flushHandler{Handler: slog.NewTextHandler(&b, nil), flushed: &flushed}
+	sl := logger.NewSlog(slog.New(h)).WithExitFunc(func(code int) { codes = append(codes, code) })
+
+	sl.Fatalf("test %s", "message")
+	sl.Fatal("test message")
+
+	c.Assert(codes, qt.DeepEquals, []int{1, 1})
+	c.Assert(flushed, qt.Equals, 2)
+	c.Assert(handlerCalls, qt.Equals, 2)
+	c.Assert(b.String(), qt.Contains, "level=ERROR+92 msg=\"test message\"\n")
+}
+
+func TestSlog_Fatal_FlushTimeout(t *testing.T) {
+	c := qt.New(t)
+
+	logger.SetExitFlushTimeout(10 * time.Millisecond)
+	defer logger.SetExitFlushTimeout(0)
+
+	blocking := newBlockingHandler(loggertest.New())
+	h := logger.NewAsyncHandler(blocking, logger.AsyncOptions{})
+	defer h.Close(context.Background())
+	defer close(blocking.release)
+
+	var codes []int
+	sl := logger.NewSlog(slog.New(h)).WithExitFunc(func(code int) { codes = append(codes, code) })
+	sl.Info("blocked")
+	<-blocking.started
+
+	// the flush gives up, so that a blocked handler does not prevent the exit
+	sl.Fatal("test message")
+	c.Assert(codes, qt.DeepEquals, []int{1})
+}
+
+func TestFatal(t *testing.T) {
+	c := qt.New(t)
+
+	var panicked bool
+	var exited bool
+	logger.RegisterExitHandler(func() {
+		if !exited {
+			exited = true
+			panic("must not prevent the exit")
+		}
+	})
+	logger.RegisterExitHandler(func() { panicked = true })
+
+	var codes []int
+	logger.SetExitFunc(func(code int) { codes = append(codes, code) })
+	defer logger.SetExitFunc(nil)
+
+	var b bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&b, nil)))
+
+	logger.Fatal("test message")
+	logger.Exit(2)
+
+	c.Assert(codes, qt.DeepEquals, []int{1, 2})
+	c.Assert(panicked, qt.IsTrue)
+	c.Assert(b.String(), qt.Contains, "msg=\"test message\"\n")
+}
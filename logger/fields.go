@@ -0,0 +1,84 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// Fields is a map of fields attached to log records. It is the counterpart of logrus.Fields.
+type Fields map[string]any
+
+// Args returns the fields as a slice of key-value pairs sorted by key,
+// suitable for Slog.WithFields and slog.Logger.With.
+func (f Fields) Args() []any {
+	args := make([]any, 0, 2*len(f))
+	for _, k := range slices.Sorted(maps.Keys(f)) {
+		args = append(args, k, f[k])
+	}
+	return args
+}
+
+var (
+	_ PrimitiveLogger                       = (*FieldsSlog)(nil)
+	_ BasicLogger                           = (*FieldsSlog)(nil)
+	_ LevelLogger                           = (*FieldsSlog)(nil)
+	_ TraceLogger                           = (*FieldsSlog)(nil)
+	_ FieldLogger[Fields, *FieldsSlog]      = (*FieldsSlog)(nil)
+	_ TraceFieldLogger[Fields, *FieldsSlog] = (*FieldsSlog)(nil)
+	_ ErrorLogger                           = (*FieldsSlog)(nil)
+)
+
+// NewFieldsSlog returns a new FieldsSlog wrapping logger.
+func NewFieldsSlog(logger *slog.Logger) *FieldsSlog {
+	return &FieldsSlog{
+		Slog: NewSlog(logger),
+	}
+}
+
+// FieldsSlog is a variant of Slog whose WithFields method accepts Fields (a map, like logrus.Fields)
+// rather than a slice of key-value pairs. It implements TraceFieldLogger[Fields, *FieldsSlog],
+// so code written against logrus.Fields can be migrated without rewriting the field maps.
+// The fields are attached in the order of their keys, so the output is deterministic.
+//
+// An existing Slog can be converted with &logger.FieldsSlog{Slog: s}.
+type FieldsSlog struct {
+	*Slog
+}
+
+func (s *FieldsSlog) WithField(key string, value any) *FieldsSlog {
+	return &FieldsSlog{Slog: s.Slog.WithField(key, value)}
+}
+
+func (s *FieldsSlog) WithFields(fields Fields) *FieldsSlog {
+	return &FieldsSlog{Slog: s.Slog.WithFields(fields.Args())}
+}
+
+func (s *FieldsSlog) WithError(err error) *FieldsSlog {
+	return &FieldsSlog{Slog: s.Slog.withError(err)}
+}
+
+// WithContext is the same as Slog.WithContext.
+func (s *FieldsSlog) WithContext(ctx context.Context) *FieldsSlog {
+	return &FieldsSlog{Slog: s.Slog.WithContext(ctx)}
+}
+
+// WithExitFunc is the same as Slog.WithExitFunc.
+func (s *FieldsSlog) WithExitFunc(fn func(code int)) *FieldsSlog {
+	return &FieldsSlog{Slog: s.Slog.WithExitFunc(fn)}
+}
+
+// Named is the same as Slog.Named.
+func (s *FieldsSlog) Named(name string) *FieldsSlog {
+	return &FieldsSlog{Slog: s.Slog.Named(name)}
+}
+
+// WithLevels is the same as Slog.WithLevels.
+func (s *FieldsSlog) WithLevels(registry *LevelRegistry) *FieldsSlog {
+	return &FieldsSlog{Slog: s.Slog.WithLevels(registry)}
+}
@@ -0,0 +1,38 @@
+package logger_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func TestFields_Args(t *testing.T) {
+	c := qt.New(t)
+
+	f := logger.Fields{"b": 2, "a": 1, "c": "3"}
+	c.Assert(f.Args(), qt.DeepEquals, []any{"a", 1, "b", 2, "c", "3"})
+	c.Assert(logger.Fields(nil).Args(), qt.HasLen, 0)
+}
+
+func TestFieldsSlog_WithFields(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	h := slog.NewTextHandler(&b, &slog.HandlerOptions{
+		ReplaceAttr: func(_groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "time" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	var fl logger.FieldLogger[logger.Fields, *logger.FieldsSlog] = logger.NewFieldsSlog(slog.New(h))
+	fl.WithFields(logger.Fields{"z": 1, "a": "x", "m": true}).WithField("k", "v").Infof("test %s", "message")
+
+	c.Assert(b.String(), qt.Equals, "level=INFO msg=\"test message\" a=x m=true z=1 k=v\n")
+}
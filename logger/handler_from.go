@@ -0,0 +1,114 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+var _ slog.Handler = (*LevelLoggerHandler)(nil)
+
+// LevelLoggerHandler is a slog.Handler that writes records to a LevelLogger.
+// It is the reverse of Slog: it lets code using *slog.Logger write to a legacy logger
+// (e.g. a logrus logger), so that a single sink serves both during a migration.
+type LevelLoggerHandler struct {
+	l      LevelLogger
+	attrs  string
+	prefix string
+}
+
+// NewHandlerFrom returns a LevelLoggerHandler that writes records to l.
+//
+// Records are passed to the method matching their level: Tracef (or Debugf, if l does not
+// implement TraceLogger), Debugf, Infof, Warnf and Errorf. Records at the fatal and panic
+// levels are passed to Errorf rather than to Fatalf and Panicf, as a handler must not
+// terminate the program (Slog.Fatal and Slog.Panic take care of that).
+//
+// Attributes are appended to the message as key=value pairs, with the keys of grouped
+// attributes prefixed with their group names (group.key). Filtering by level is left to l.
+// PrimitiveLogger implementations can be used with Upgrade.
+func NewHandlerFrom(l LevelLogger) *LevelLoggerHandler {
+	return &LevelLoggerHandler{
+		l: l,
+	}
+}
+
+// Enabled always returns true: filtering by level is left to the underlying logger.
+func (*LevelLoggerHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle writes the record to the method of the underlying logger matching its level.
+func (h *LevelLoggerHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		for _, f := range appendFlatField(nil, h.prefix, a) {
+			appendKeyValueText(&b, f)
+		}
+		return true
+	})
+
+	logf := h.l.Errorf
+	switch {
+	case r.Level < slog.LevelDebug:
+		logf = h.l.Debugf
+		if tl, ok := h.l.(TraceLogger); ok {
+			logf = tl.Tracef
+		}
+	case r.Level < slog.LevelInfo:
+		logf = h.l.Debugf
+	case r.Level < slog.LevelWarn:
+		logf = h.l.Infof
+	case r.Level < slog.LevelError:
+		logf = h.l.Warnf
+	}
+	logf("%s", b.String())
+	return nil
+}
+
+// WithAttrs returns a new LevelLoggerHandler that appends the given attributes to every message.
+func (h *LevelLoggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, a := range attrs {
+		for _, f := range appendFlatField(nil, h.prefix, a) {
+			appendKeyValueText(&b, f)
+		}
+	}
+	r := *h
+	r.attrs = b.String()
+	return &r
+}
+
+// WithGroup returns a new LevelLoggerHandler that prefixes the keys of the subsequent attributes with name.
+func (h *LevelLoggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	r := *h
+	r.prefix += name + "."
+	return &r
+}
+
+// appendKeyValueText appends " key=value" to b, quoting the value if needed.
+func appendKeyValueText(b *strings.Builder, f flatField) {
+	s, ok := f.value.(string)
+	if !ok {
+		s = fmt.Sprint(f.value)
+	}
+	b.WriteByte(' ')
+	b.WriteString(f.key)
+	b.WriteByte('=')
+	if needsQuoting(s) {
+		b.WriteString(fmt.Sprintf("%q", s))
+	} else {
+		b.WriteString(s)
+	}
+}
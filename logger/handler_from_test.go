@@ -0,0 +1,57 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func newLegacyLogger(b *bytes.Buffer) *logger.Slog {
+	return logger.NewSlog(slog.New(slog.NewTextHandler(b, &slog.HandlerOptions{
+		Level: logger.SlogLevelTrace,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "time" {
+				return slog.Attr{}
+			}
+			return logger.ReplaceLevelAttr(groups, a)
+		},
+	})))
+}
+
+func TestNewHandlerFrom(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	l := slog.New(logger.NewHandlerFrom(newLegacyLogger(&b)))
+
+	l.Log(context.Background(), logger.SlogLevelTrace, "trace")
+	l.Debug("debug", "k", "v")
+	l.With("a", 1).WithGroup("g").Info("info", "k", "with space", slog.Group("h", "n", 2))
+	l.Warn("warn", "error", errors.New("boom"))
+	l.Error("error")
+	l.Log(context.Background(), logger.SlogLevelFatal, "fatal")
+
+	c.Assert(b.String(), qt.Equals, `level=TRACE msg=trace
+level=DEBUG msg="debug k=v"
+level=INFO msg="info a=1 g.k=\"with space\" g.h.n=2"
+level=WARN msg="warn error=boom"
+level=ERROR msg=error
+level=ERROR msg=fatal
+`)
+}
+
+func TestNewHandlerFrom_NoTrace(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	l := slog.New(logger.NewHandlerFrom(struct{ logger.LevelLogger }{newLegacyLogger(&b)}))
+	l.Log(context.Background(), logger.SlogLevelTrace, "trace")
+
+	c.Assert(b.String(), qt.Equals, "level=DEBUG msg=trace\n")
+}
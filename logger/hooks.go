@@ -0,0 +1,97 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Hook is called for every record of the levels it declares, the same way logrus hooks are.
+// It can be used, for example, to send errors to an error tracker or to count log lines by level.
+type Hook interface {
+	// Levels returns the levels the hook is fired for.
+	Levels() []slog.Level
+	// Fire is called with every record of one of the levels returned by Levels.
+	// The record includes the fields attached to the logger (see Slog.WithField).
+	// It is not redacted by a RedactHandler of the logger; see RedactHook.
+	// A returned error is reported to stderr and does not prevent the record from being logged.
+	Fire(ctx context.Context, r slog.Record) error
+}
+
+// AllLevels contains all the levels defined by this package and by log/slog.
+// It can be returned by Hook.Levels to fire a hook for every record.
+var AllLevels = []slog.Level{
+	SlogLevelTrace,
+	slog.LevelDebug,
+	slog.LevelInfo,
+	slog.LevelWarn,
+	slog.LevelError,
+	SlogLevelFatal,
+	SlogLevelPanic,
+}
+
+// stdHooks are the hooks of the package-level functions.
+var stdHooks hookSet
+
+// hookSet is a set of hooks shared by a logger and all the loggers derived from it.
+type hookSet struct {
+	mu    sync.RWMutex
+	hooks map[slog.Level][]Hook
+}
+
+func (hs *hookSet) add(hook Hook) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hs.hooks == nil {
+		hs.hooks = make(map[slog.Level][]Hook)
+	}
+	for _, level := range hook.Levels() {
+		hs.hooks[level] = append(hs.hooks[level], hook)
+	}
+}
+
+// fire calls the hooks of the record's level with the record extended with attrs.
+func (hs *hookSet) fire(ctx context.Context, r slog.Record, attrs []slog.Attr) {
+	if hs == nil {
+		return
+	}
+	hs.mu.RLock()
+	hooks := hs.hooks[r.Level]
+	hs.mu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+
+	if len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	for _, hook := range hooks {
+		if err := hook.Fire(ctx, r); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: failed to fire hook: %v\n", err)
+		}
+	}
+}
+
+// AddHook adds a hook to the logger. The hook is shared with all the loggers derived from it
+// (e.g. with WithField), including the ones derived before the call, the same way logrus hooks
+// are shared by all the entries of a logger.
+// It is safe for concurrent use, unless the logger was not created by NewSlog.
+func (s *Slog) AddHook(hook Hook) {
+	if s.hooks == nil {
+		s.hooks = &hookSet{}
+	}
+	s.hooks.add(hook)
+}
+
+// AddHook adds a hook to the package-level functions and the loggers returned by
+// the package-level WithField, WithFields, WithError and WithContext functions.
+func AddHook(hook Hook) {
+	stdHooks.add(hook)
+}
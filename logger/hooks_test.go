@@ -0,0 +1,84 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+type testHook struct {
+	levels  []slog.Level
+	records []slog.Record
+	err     error
+}
+
+func (h *testHook) Levels() []slog.Level {
+	return h.levels
+}
+
+func (h *testHook) Fire(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return h.err
+}
+
+func recordAttrs(r slog.Record) map[string]any {
+	attrs := make(map[string]any)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.Any()
+		return true
+	})
+	return attrs
+}
+
+func TestSlog_AddHook(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(&b, nil)))
+	derived := sl.WithField("k", "v")
+
+	errHook := &testHook{levels: []slog.Level{slog.LevelError}, err: errors.New("hook failed")}
+	allHook := &testHook{levels: logger.AllLevels}
+	sl.AddHook(errHook)
+	sl.AddHook(allHook)
+
+	derived.Errorf("test %s", "error")
+	sl.Info("test info")
+	sl.Debug("disabled")
+
+	c.Assert(errHook.records, qt.HasLen, 1)
+	c.Assert(errHook.records[0].Message, qt.Equals, "test error")
+	c.Assert(errHook.records[0].Level, qt.Equals, slog.LevelError)
+	c.Assert(recordAttrs(errHook.records[0]), qt.DeepEquals, map[string]any{"k": "v"})
+
+	c.Assert(allHook.records, qt.HasLen, 2)
+	c.Assert(allHook.records[1].Message, qt.Equals, "test info")
+
+	// hook errors do not prevent logging
+	c.Assert(b.String(), qt.Contains, "level=ERROR msg=\"test error\" k=v\n")
+	c.Assert(b.String(), qt.Not(qt.Contains), "hook")
+}
+
+func TestAddHook(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&b, nil)))
+
+	hook := &testHook{levels: []slog.Level{slog.LevelWarn}}
+	logger.AddHook(hook)
+
+	logger.Warnf("test %s", "warning")
+	logger.WithField("k", "v").Warning("test warning")
+	logger.Info("test info")
+
+	c.Assert(hook.records, qt.HasLen, 2)
+	c.Assert(recordAttrs(hook.records[1]), qt.DeepEquals, map[string]any{"k": "v"})
+}
@@ -0,0 +1,138 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Levels that are not defined by log/slog.
+// Use Level, ReplaceLevelAttr or ParseLevel to work with their names.
+const (
+	SlogLevelTrace slog.Level = slog.LevelDebug - 4
+	SlogLevelFatal slog.Level = 100
+	SlogLevelPanic slog.Level = 104
+)
+
+var levelNames = []struct {
+	level slog.Level
+	name  string
+}{
+	{SlogLevelTrace, "TRACE"},
+	{slog.LevelDebug, "DEBUG"},
+	{slog.LevelInfo, "INFO"},
+	{slog.LevelWarn, "WARN"},
+	{slog.LevelError, "ERROR"},
+	{SlogLevelFatal, "FATAL"},
+	{SlogLevelPanic, "PANIC"},
+}
+
+var _ slog.Leveler = Level(0)
+
+// Level is a slog.Level that is aware of the TRACE, FATAL and PANIC levels defined by this package.
+// It can be used anywhere a slog.Leveler is accepted and can be read from config files or env vars
+// as it implements encoding.TextMarshaler and encoding.TextUnmarshaler.
+type Level slog.Level
+
+// ParseLevel parses a level name, such as "trace", "info" or "fatal", case-insensitively.
+// Like slog.Level.UnmarshalText, it accepts an optional offset, e.g. "debug+2" or "error-1".
+// "warning" is accepted as an alias of "warn".
+func ParseLevel(s string) (Level, error) {
+	name, offset := s, 0
+	if i := strings.IndexAny(s[min(1, len(s)):], "+-"); i >= 0 {
+		i++ // account for the skipped first byte
+		var err error
+		name = s[:i]
+		if offset, err = strconv.Atoi(s[i:]); err != nil {
+			return 0, fmt.Errorf("logger: level string %q: %w", s, err)
+		}
+	}
+
+	name = strings.ToUpper(name)
+	if name == "WARNING" {
+		name = "WARN"
+	}
+	for _, n := range levelNames {
+		if n.name == name {
+			return Level(n.level + slog.Level(offset)), nil
+		}
+	}
+	return 0, fmt.Errorf("logger: level string %q: unknown name", s)
+}
+
+// Level returns the slog.Level value of l. It implements slog.Leveler.
+func (l Level) Level() slog.Level {
+	return slog.Level(l)
+}
+
+// String returns the name of the level. Levels between the named ones
+// are represented as the closest lower name plus an offset, e.g. "ERROR+2".
+func (l Level) String() string {
+	base, name := l.base()
+	if slog.Level(l) == base {
+		return name
+	}
+	return fmt.Sprintf("%s%+d", name, slog.Level(l)-base)
+}
+
+// base returns the closest named level that is not greater than l, along with its name.
+func (l Level) base() (slog.Level, string) {
+	base := levelNames[0]
+	for _, n := range levelNames[1:] {
+		if slog.Level(l) >= n.level {
+			base = n
+		}
+	}
+	return base.level, base.name
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. See ParseLevel for the accepted format.
+func (l *Level) UnmarshalText(data []byte) error {
+	lvl, err := ParseLevel(string(data))
+	if err != nil {
+		return err
+	}
+	*l = lvl
+	return nil
+}
+
+// ReplaceLevelAttr renders the level attribute of a record with the names known to this package
+// (TRACE, FATAL, PANIC) instead of slog's default ones (e.g. "ERROR+92").
+// Use it as slog.HandlerOptions.ReplaceAttr, or combine it with other functions using ReplaceAttrs:
+//
+//	h := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+//		Level:       logger.SlogLevelTrace,
+//		ReplaceAttr: logger.ReplaceLevelAttr,
+//	})
+func ReplaceLevelAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 || a.Key != slog.LevelKey {
+		return a
+	}
+	if lvl, ok := a.Value.Any().(slog.Level); ok {
+		a.Value = slog.StringValue(Level(lvl).String())
+	}
+	return a
+}
+
+// ReplaceAttrs combines several slog.HandlerOptions.ReplaceAttr functions into one.
+// The functions are applied in order; nil functions are skipped.
+func ReplaceAttrs(fns ...func(groups []string, a slog.Attr) slog.Attr) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		for _, fn := range fns {
+			if fn != nil {
+				a = fn(groups, a)
+			}
+		}
+		return a
+	}
+}
@@ -0,0 +1,59 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+var (
+	_ slog.Handler = (*LevelHandler)(nil)
+	_ Flusher      = (*LevelHandler)(nil)
+)
+
+// LevelHandler is a slog.Handler wrapper that only passes records at or above a minimum level
+// to the wrapped handler. It is useful to give each sink of a MultiHandler its own level,
+// or to raise the level of a handler without rebuilding it.
+// As a wrapper can only narrow what the wrapped handler handles, records below the level
+// of the wrapped handler are dropped too.
+type LevelHandler struct {
+	level slog.Leveler
+	next  slog.Handler
+}
+
+// NewLevelHandler returns a LevelHandler that passes the records at or above level to next.
+// The level can be changed at runtime by passing a *slog.LevelVar.
+func NewLevelHandler(level slog.Leveler, next slog.Handler) *LevelHandler {
+	return &LevelHandler{
+		level: level,
+		next:  next,
+	}
+}
+
+// Enabled reports whether level is at or above the minimum level and the wrapped handler handles it.
+func (h *LevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.next.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler.
+func (h *LevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a new LevelHandler whose wrapped handler has the given attributes.
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewLevelHandler(h.level, h.next.WithAttrs(attrs))
+}
+
+// WithGroup returns a new LevelHandler whose wrapped handler has the given group.
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	return NewLevelHandler(h.level, h.next.WithGroup(name))
+}
+
+// Flush flushes the wrapped handler if it implements Flusher.
+func (h *LevelHandler) Flush(ctx context.Context) error {
+	return flushHandler(ctx, h.next)
+}
@@ -0,0 +1,34 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+func TestLevelHandler(t *testing.T) {
+	c := qt.New(t)
+
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+
+	rec := loggertest.New()
+	sl := logger.NewSlog(slog.New(logger.NewLevelHandler(&level, rec)))
+	sl.WithField("k", "v").Info("info 1")
+	sl.Warn("warn")
+	level.Set(slog.LevelInfo)
+	sl.WithField("k", "v").Info("info 2")
+
+	rec.AssertNoEntry(t, slog.LevelInfo, "info 1")
+	rec.AssertEntry(t, slog.LevelWarn, "warn")
+	rec.AssertEntry(t, slog.LevelInfo, "info 2", slog.String("k", "v"))
+
+	h := logger.NewLevelHandler(slog.LevelDebug, logger.NewLevelHandler(slog.LevelInfo, rec))
+	c.Assert(h.Enabled(context.Background(), slog.LevelDebug), qt.IsFalse)
+	c.Assert(h.Enabled(context.Background(), slog.LevelInfo), qt.IsTrue)
+}
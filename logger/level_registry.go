@@ -0,0 +1,229 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// unsetLevel is the level of the names without a configured level: it lets the handler decide.
+// It is never stored in the registry.
+const unsetLevel = slog.Level(math.MinInt)
+
+// checkLevel returns an error if level is unsetLevel, which cannot be configured.
+func checkLevel(level slog.Level) error {
+	if level == unsetLevel {
+		return fmt.Errorf("logger: level %s is reserved", Level(level))
+	}
+	return nil
+}
+
+var defaultLevels = NewLevelRegistry()
+
+// DefaultLevels returns the registry used by the loggers built with Slog.Named,
+// unless another one is set with Slog.WithLevels.
+func DefaultLevels() *LevelRegistry {
+	return defaultLevels
+}
+
+// LevelRegistry holds the minimum levels of named loggers (see Slog.Named) by name prefix.
+// The level of a logger is the level of the longest configured prefix of its name,
+// matched on dots: "db" applies to "db" and "db.pool" but not to "dbx".
+// The empty prefix applies to all the loggers. If no prefix matches, only the level
+// of the handler applies.
+//
+// Levels are stored in slog.LevelVar values, so they can be changed at runtime.
+// As named loggers can only narrow what their handler handles, the handler should be configured
+// with the lowest level that a prefix may need (e.g. SlogLevelTrace) and the default level
+// set in the registry with the empty prefix.
+//
+// LevelRegistry implements http.Handler to view and change the levels live (see ServeHTTP).
+type LevelRegistry struct {
+	mu     sync.RWMutex
+	levels map[string]*slog.LevelVar
+}
+
+// NewLevelRegistry returns an empty LevelRegistry.
+func NewLevelRegistry() *LevelRegistry {
+	return &LevelRegistry{
+		levels: make(map[string]*slog.LevelVar),
+	}
+}
+
+// Level returns the level of the longest configured prefix of name. If no prefix is configured,
+// it returns a level below all the others, so that only the level of the handler applies.
+func (r *LevelRegistry) Level(name string) slog.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for {
+		if v, ok := r.levels[name]; ok {
+			return v.Level()
+		}
+		if name == "" {
+			return unsetLevel
+		}
+		i := strings.LastIndexByte(name, '.')
+		name = name[:max(i, 0)]
+	}
+}
+
+// LevelVar returns the variable holding the level of prefix, which can be used to change it.
+// If the prefix has no level yet, it is created with the level of its longest configured prefix,
+// or slog.LevelInfo if there is none.
+func (r *LevelRegistry) LevelVar(prefix string) *slog.LevelVar {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.levelVarLocked(prefix)
+}
+
+func (r *LevelRegistry) levelVarLocked(prefix string) *slog.LevelVar {
+	if v, ok := r.levels[prefix]; ok {
+		return v
+	}
+
+	level := slog.LevelInfo
+	for name := prefix; name != ""; {
+		i := strings.LastIndexByte(name, '.')
+		name = name[:max(i, 0)]
+		if v, ok := r.levels[name]; ok {
+			level = v.Level()
+			break
+		}
+	}
+	v := &slog.LevelVar{}
+	v.Set(level)
+	r.levels[prefix] = v
+	return v
+}
+
+// Set sets the level of prefix. The level math.MinInt is reserved and treated as slog.LevelInfo.
+func (r *LevelRegistry) Set(prefix string, level slog.Level) {
+	if checkLevel(level) != nil {
+		level = slog.LevelInfo
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.levelVarLocked(prefix).Set(level)
+}
+
+// Unset removes the level of prefix, so that the level of a shorter prefix applies.
+func (r *LevelRegistry) Unset(prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.levels, prefix)
+}
+
+// Levels returns the configured levels by prefix.
+func (r *LevelRegistry) Levels() map[string]Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := make(map[string]Level, len(r.levels))
+	for prefix, v := range r.levels {
+		levels[prefix] = Level(v.Level())
+	}
+	return levels
+}
+
+// Parse sets the levels listed in spec, a comma-separated list of prefix=level pairs, e.g.
+// "db=debug,http=warn". An item without a prefix, e.g. "info", sets the default level.
+// The levels are parsed with ParseLevel. If spec is malformed, no level is changed.
+// The levels of the prefixes not listed in spec are kept.
+func (r *LevelRegistry) Parse(spec string) error {
+	levels := make(map[string]slog.Level)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		prefix, name, ok := strings.Cut(item, "=")
+		if !ok {
+			prefix, name = "", item
+		}
+		level, err := ParseLevel(strings.TrimSpace(name))
+		if err == nil {
+			err = checkLevel(level.Level())
+		}
+		if err != nil {
+			return fmt.Errorf("logger: level spec item %q: %w", item, err)
+		}
+		levels[strings.TrimSpace(prefix)] = level.Level()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for prefix, level := range levels {
+		r.levelVarLocked(prefix).Set(level)
+	}
+	return nil
+}
+
+// leveler returns a slog.Leveler that looks up the level of name on every call,
+// so that it reflects the changes of the registry.
+func (r *LevelRegistry) leveler(name string) slog.Leveler {
+	return nameLeveler{registry: r, name: name}
+}
+
+type nameLeveler struct {
+	registry *LevelRegistry
+	name     string
+}
+
+func (l nameLeveler) Level() slog.Level {
+	return l.registry.Level(l.name)
+}
+
+// ServeHTTP serves the levels of the registry as a JSON object mapping the prefixes
+// to the level names, e.g. {"": "INFO", "db": "DEBUG"}.
+// GET returns the levels. PUT takes an object of the same form and sets the listed levels;
+// a null level unsets the prefix. It responds with the resulting levels.
+func (r *LevelRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut:
+		var levels map[string]*Level
+		if err := json.NewDecoder(req.Body).Decode(&levels); err != nil {
+			http.Error(w, fmt.Sprintf("invalid levels: %v", err), http.StatusBadRequest)
+			return
+		}
+		for prefix, level := range levels {
+			if level == nil {
+				continue
+			}
+			if err := checkLevel(level.Level()); err != nil {
+				http.Error(w, fmt.Sprintf("invalid level of %q: %v", prefix, err), http.StatusBadRequest)
+				return
+			}
+		}
+		r.mu.Lock()
+		for prefix, level := range levels {
+			if level == nil {
+				delete(r.levels, prefix)
+				continue
+			}
+			r.levelVarLocked(prefix).Set(level.Level())
+		}
+		r.mu.Unlock()
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(r.Levels()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
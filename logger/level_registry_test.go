@@ -0,0 +1,112 @@
+package logger_test
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func TestLevelRegistry(t *testing.T) {
+	c := qt.New(t)
+
+	r := logger.NewLevelRegistry()
+	c.Assert(r.Level("db") < logger.SlogLevelTrace, qt.IsTrue)
+
+	c.Assert(r.Parse("info, db=debug,db.pool=warning"), qt.IsNil)
+	c.Assert(r.Level(""), qt.Equals, slog.LevelInfo)
+	c.Assert(r.Level("http"), qt.Equals, slog.LevelInfo)
+	c.Assert(r.Level("db"), qt.Equals, slog.LevelDebug)
+	c.Assert(r.Level("db.conn"), qt.Equals, slog.LevelDebug)
+	c.Assert(r.Level("db.pool.idle"), qt.Equals, slog.LevelWarn)
+	c.Assert(r.Level("dbx"), qt.Equals, slog.LevelInfo)
+
+	r.LevelVar("db").Set(slog.LevelError)
+	c.Assert(r.Level("db.conn"), qt.Equals, slog.LevelError)
+
+	// new variables start at the level that applies to the prefix
+	c.Assert(r.LevelVar("db.conn").Level(), qt.Equals, slog.LevelError)
+
+	r.Unset("db.pool")
+	c.Assert(r.Level("db.pool.idle"), qt.Equals, slog.LevelError)
+	r.Set("db.pool", logger.SlogLevelTrace)
+	c.Assert(r.Levels(), qt.DeepEquals, map[string]logger.Level{
+		"":        logger.Level(slog.LevelInfo),
+		"db":      logger.Level(slog.LevelError),
+		"db.conn": logger.Level(slog.LevelError),
+		"db.pool": logger.Level(logger.SlogLevelTrace),
+	})
+}
+
+func TestLevelRegistry_ParseError(t *testing.T) {
+	c := qt.New(t)
+
+	r := logger.NewLevelRegistry()
+	err := r.Parse("db=debug,http=loud")
+	c.Assert(err, qt.ErrorMatches, `logger: level spec item "http=loud": logger: level string "loud": unknown name`)
+	c.Assert(r.Levels(), qt.HasLen, 0)
+
+	err = r.Parse("db=TRACE-9223372036854775800")
+	c.Assert(err, qt.ErrorMatches, `logger: level spec item "db=TRACE-9223372036854775800": logger: level TRACE-9223372036854775800 is reserved`)
+	c.Assert(r.Levels(), qt.HasLen, 0)
+}
+
+func TestLevelRegistry_Default(t *testing.T) {
+	c := qt.New(t)
+
+	// new variables of prefixes without a configured ancestor start at the default level
+	r := logger.NewLevelRegistry()
+	c.Assert(r.LevelVar("db").Level(), qt.Equals, slog.LevelInfo)
+	r.Set("http", slog.Level(math.MinInt))
+	c.Assert(r.Levels(), qt.DeepEquals, map[string]logger.Level{
+		"db":   logger.Level(slog.LevelInfo),
+		"http": logger.Level(slog.LevelInfo),
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/levels", nil))
+	c.Assert(w.Body.String(), qt.Equals, `{"db":"INFO","http":"INFO"}`+"\n")
+}
+
+func TestLevelRegistry_ServeHTTP(t *testing.T) {
+	c := qt.New(t)
+
+	r := logger.NewLevelRegistry()
+	c.Assert(r.Parse("info,db=debug"), qt.IsNil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/levels", nil))
+	c.Assert(w.Code, qt.Equals, http.StatusOK)
+	c.Assert(w.Header().Get("Content-Type"), qt.Equals, "application/json")
+	c.Assert(w.Body.String(), qt.Equals, `{"":"INFO","db":"DEBUG"}`+"\n")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/levels", strings.NewReader(`{"db":null,"http":"trace"}`)))
+	c.Assert(w.Code, qt.Equals, http.StatusOK)
+	c.Assert(w.Body.String(), qt.Equals, `{"":"INFO","http":"TRACE"}`+"\n")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/levels", strings.NewReader(`{"db":"loud"}`)))
+	c.Assert(w.Code, qt.Equals, http.StatusBadRequest)
+	c.Assert(w.Body.String(), qt.Contains, `unknown name`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/levels", strings.NewReader(`{"db":"TRACE-9223372036854775800","x":"info"}`)))
+	c.Assert(w.Code, qt.Equals, http.StatusBadRequest)
+	c.Assert(w.Body.String(), qt.Contains, `is reserved`)
+	c.Assert(r.Levels(), qt.DeepEquals, map[string]logger.Level{
+		"":     logger.Level(slog.LevelInfo),
+		"http": logger.Level(logger.SlogLevelTrace),
+	})
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/levels", nil))
+	c.Assert(w.Code, qt.Equals, http.StatusMethodNotAllowed)
+	c.Assert(w.Header().Get("Allow"), qt.Equals, "GET, HEAD, PUT")
+}
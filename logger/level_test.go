@@ -0,0 +1,113 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func TestLevel_String(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{logger.SlogLevelTrace, "TRACE"},
+		{logger.SlogLevelTrace - 2, "TRACE-2"},
+		{slog.LevelDebug, "DEBUG"},
+		{slog.LevelDebug + 1, "DEBUG+1"},
+		{slog.LevelInfo, "INFO"},
+		{slog.LevelWarn, "WARN"},
+		{slog.LevelError, "ERROR"},
+		{slog.LevelError + 2, "ERROR+2"},
+		{logger.SlogLevelFatal, "FATAL"},
+		{logger.SlogLevelPanic, "PANIC"},
+		{logger.SlogLevelPanic + 1, "PANIC+1"},
+	}
+	for _, tt := range tests {
+		c.Check(logger.Level(tt.level).String(), qt.Equals, tt.want)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		in   string
+		want slog.Level
+	}{
+		{"trace", logger.SlogLevelTrace},
+		{"TRACE-2", logger.SlogLevelTrace - 2},
+		{"Debug", slog.LevelDebug},
+		{"info", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{"error+2", slog.LevelError + 2},
+		{"fatal", logger.SlogLevelFatal},
+		{"panic", logger.SlogLevelPanic},
+	}
+	for _, tt := range tests {
+		lvl, err := logger.ParseLevel(tt.in)
+		c.Assert(err, qt.IsNil, qt.Commentf("input: %q", tt.in))
+		c.Check(lvl.Level(), qt.Equals, tt.want, qt.Commentf("input: %q", tt.in))
+	}
+
+	for _, in := range []string{"", "verbose", "info+x", "+1"} {
+		_, err := logger.ParseLevel(in)
+		c.Check(err, qt.IsNotNil, qt.Commentf("input: %q", in))
+	}
+}
+
+func TestLevel_MarshalText(t *testing.T) {
+	c := qt.New(t)
+
+	var cfg struct {
+		Level logger.Level `json:"level"`
+	}
+	err := json.Unmarshal([]byte(`{"level":"fatal"}`), &cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cfg.Level.Level(), qt.Equals, logger.SlogLevelFatal)
+
+	data, err := json.Marshal(cfg)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, `{"level":"FATAL"}`)
+
+	err = json.Unmarshal([]byte(`{"level":"nope"}`), &cfg)
+	c.Assert(err, qt.ErrorMatches, `logger: level string "nope": unknown name`)
+}
+
+func TestReplaceLevelAttr(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	h := slog.NewTextHandler(&b, &slog.HandlerOptions{
+		Level: logger.SlogLevelTrace,
+		ReplaceAttr: logger.ReplaceAttrs(
+			func(_groups []string, a slog.Attr) slog.Attr {
+				if a.Key == "time" {
+					return slog.Attr{}
+				}
+				return a
+			},
+			logger.ReplaceLevelAttr,
+		),
+	})
+
+	l := slog.New(h)
+	l.Log(context.Background(), logger.SlogLevelTrace, "trace")
+	l.Log(context.Background(), logger.SlogLevelFatal, "fatal")
+	l.Log(context.Background(), logger.SlogLevelPanic, "panic")
+	l.WithGroup("g").Info("info", slog.String("level", "x")) // nested level keys are left alone
+
+	c.Assert(b.String(), qt.Equals, "level=TRACE msg=trace\n"+
+		"level=FATAL msg=fatal\n"+
+		"level=PANIC msg=panic\n"+
+		"level=INFO msg=info g.level=x\n")
+}
@@ -1,6 +1,10 @@
 // Package logger provides interfaces for logging with various levels of verbosity and functionality.
 // It also provides a convenient (but experimental) way of migrating from logrus to slog.
 //
+// Secrets can be redacted from the records with RedactHandler. Hooks (see Hook) are fired with
+// the records before they reach the handler of the logger, so they see the secrets unless they
+// are wrapped in a RedactHook.
+//
 // License: MIT
 // Copyright: 2023, Denis Voytyuk
 package logger
@@ -63,3 +67,10 @@ type TraceFieldLogger[T any, U any] interface {
 	TraceLogger
 	FieldLogger[T, U]
 }
+
+// ErrorLogger is a logger interface that provides methods for logging an error and returning it at once,
+// so that the log record and the returned error carry the same structured data (see AttrsFromError).
+type ErrorLogger interface {
+	ErrorfErr(format string, args ...any) error
+	WrapErr(err error, msg string, attrs ...any) error
+}
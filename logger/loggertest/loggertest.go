@@ -0,0 +1,425 @@
+// Package loggertest provides a recording logger for testing code that logs through
+// the interfaces of the logger package or through log/slog.
+//
+// Example usage:
+//
+//	rec := loggertest.New()
+//	p := pubsub.NewPublisher[string](1, pubsub.WithLogger[string](rec))
+//	// ...
+//	rec.AssertEntry(t, slog.LevelInfo, "dropping message")
+//
+// The same recorder can be used as a slog.Handler:
+//
+//	sl := logger.NewSlog(slog.New(rec))
+//	sl.WithField("user", "alice").Info("logged in")
+//	rec.AssertEntry(t, slog.LevelInfo, "logged in", slog.String("user", "alice"))
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package loggertest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+// Entry is a recorded log entry.
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Attrs are the attributes of the entry, including the ones attached to the logger
+	// or handler it was recorded by. Attributes added after slog.Logger.WithGroup are
+	// nested in groups.
+	Attrs []slog.Attr
+	// Source is the location the entry was logged from. It is nil if it is unknown.
+	Source *slog.Source
+}
+
+// Attr returns the value of the attribute with the given key. Keys of attributes in groups
+// are dotted paths, e.g. "request.method".
+func (e Entry) Attr(key string) (slog.Value, bool) {
+	attrs := e.Attrs
+	path := strings.Split(key, ".")
+	for i, name := range path {
+		idx := slices.IndexFunc(attrs, func(a slog.Attr) bool { return a.Key == name })
+		if idx < 0 {
+			return slog.Value{}, false
+		}
+		v := attrs[idx].Value.Resolve()
+		if i == len(path)-1 {
+			return v, true
+		}
+		if v.Kind() != slog.KindGroup {
+			return slog.Value{}, false
+		}
+		attrs = v.Group()
+	}
+	return slog.Value{}, false
+}
+
+// Matches reports whether the entry has the given level, its message contains msgSubstring,
+// and it has all the given attributes (see Attr for the keys of attributes in groups).
+func (e Entry) Matches(level slog.Level, msgSubstring string, attrs ...slog.Attr) bool {
+	if e.Level != level || !strings.Contains(e.Message, msgSubstring) {
+		return false
+	}
+	for _, a := range attrs {
+		v, ok := e.Attr(a.Key)
+		if !ok || !valuesEqual(v, a.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a human-readable representation of the entry.
+func (e Entry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %q", logger.Level(e.Level), e.Message)
+	for _, a := range e.Attrs {
+		fmt.Fprintf(&b, " %s", a)
+	}
+	return b.String()
+}
+
+func valuesEqual(v, w slog.Value) bool {
+	v, w = v.Resolve(), w.Resolve()
+	return reflect.DeepEqual(v.Any(), w.Any())
+}
+
+var (
+	_ logger.PrimitiveLogger                            = (*Recorder)(nil)
+	_ logger.BasicLogger                                = (*Recorder)(nil)
+	_ logger.LevelLogger                                = (*Recorder)(nil)
+	_ logger.TraceLogger                                = (*Recorder)(nil)
+	_ logger.FieldLogger[logger.Fields, *Recorder]      = (*Recorder)(nil)
+	_ logger.TraceFieldLogger[logger.Fields, *Recorder] = (*Recorder)(nil)
+	_ logger.ErrorLogger                                = (*Recorder)(nil)
+	_ slog.Handler                                      = (*Recorder)(nil)
+)
+
+// Recorder records log entries in memory. It implements all the interfaces of the logger package
+// and slog.Handler. Recorders derived from a Recorder (with WithField, WithAttrs, etc.) share
+// its entries, so the entries can be queried from the original one.
+//
+// Fatal and Fatalf record an entry at the logger.SlogLevelFatal level and do not exit.
+// Panic and Panicf record an entry at the logger.SlogLevelPanic level and panic with
+// a *logger.PanicValue, like logger.Slog.
+//
+// A Recorder is safe for concurrent use.
+type Recorder struct {
+	store  *store
+	frames []frame
+}
+
+// store holds the entries shared by a recorder and all the recorders derived from it.
+type store struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// frame holds the attributes added to a group (the first frame being the top level).
+type frame struct {
+	group string
+	attrs []slog.Attr
+}
+
+// New returns a new empty Recorder.
+func New() *Recorder {
+	return &Recorder{
+		store:  &store{},
+		frames: []frame{{}},
+	}
+}
+
+// Entries returns a copy of the recorded entries.
+func (r *Recorder) Entries() []Entry {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return slices.Clone(r.store.entries)
+}
+
+// Len returns the number of recorded entries.
+func (r *Recorder) Len() int {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return len(r.store.entries)
+}
+
+// Reset removes all the recorded entries.
+func (r *Recorder) Reset() {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.entries = nil
+}
+
+// FindEntries returns the entries matching the arguments (see Entry.Matches).
+func (r *Recorder) FindEntries(level slog.Level, msgSubstring string, attrs ...slog.Attr) []Entry {
+	found := make([]Entry, 0)
+	for _, e := range r.Entries() {
+		if e.Matches(level, msgSubstring, attrs...) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// HasEntry reports whether an entry matching the arguments was recorded (see Entry.Matches).
+func (r *Recorder) HasEntry(level slog.Level, msgSubstring string, attrs ...slog.Attr) bool {
+	return len(r.FindEntries(level, msgSubstring, attrs...)) > 0
+}
+
+// AssertEntry marks the test as failed if no entry matching the arguments was recorded
+// (see Entry.Matches). The recorded entries are included in the failure message.
+func (r *Recorder) AssertEntry(t testing.TB, level slog.Level, msgSubstring string, attrs ...slog.Attr) {
+	t.Helper()
+	if !r.HasEntry(level, msgSubstring, attrs...) {
+		t.Errorf("no entry matching level=%s msg=*%s* attrs=%v found in:\n%s", logger.Level(level), msgSubstring, attrs, r.dump())
+	}
+}
+
+// AssertNoEntry marks the test as failed if an entry matching the arguments was recorded
+// (see Entry.Matches). The recorded entries are included in the failure message.
+func (r *Recorder) AssertNoEntry(t testing.TB, level slog.Level, msgSubstring string, attrs ...slog.Attr) {
+	t.Helper()
+	if r.HasEntry(level, msgSubstring, attrs...) {
+		t.Errorf("unexpected entry matching level=%s msg=*%s* attrs=%v found in:\n%s", logger.Level(level), msgSubstring, attrs, r.dump())
+	}
+}
+
+func (r *Recorder) dump() string {
+	entries := r.Entries()
+	if len(entries) == 0 {
+		return "\t(no entries)"
+	}
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lines = append(lines, "\t"+e.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Enabled always returns true: the recorder records entries of all levels.
+func (*Recorder) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle records the record.
+func (r *Recorder) Handle(_ context.Context, rec slog.Record) error {
+	attrs := make([]slog.Attr, 0, rec.NumAttrs())
+	rec.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	var src *slog.Source
+	if rec.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+		src = &slog.Source{Function: f.Function, File: f.File, Line: f.Line}
+	}
+
+	r.add(Entry{
+		Time:    rec.Time,
+		Level:   rec.Level,
+		Message: rec.Message,
+		Attrs:   r.attrs(attrs),
+		Source:  src,
+	})
+	return nil
+}
+
+// WithAttrs returns a Recorder sharing the entries of r that adds attrs to every entry.
+func (r *Recorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return r.withAttrs(attrs)
+}
+
+// WithGroup returns a Recorder sharing the entries of r that nests the subsequent attributes in a group.
+func (r *Recorder) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return r
+	}
+	return &Recorder{
+		store:  r.store,
+		frames: append(slices.Clip(r.frames), frame{group: name}),
+	}
+}
+
+func (r *Recorder) Printf(format string, args ...any) {
+	r.record(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) Print(args ...any) {
+	r.record(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+func (r *Recorder) Fatalf(format string, args ...any) {
+	r.record(logger.SlogLevelFatal, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) Panicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	r.record(logger.SlogLevelPanic, msg)
+	panic(&logger.PanicValue{Message: msg, Attrs: r.attrs(nil)})
+}
+
+func (r *Recorder) Fatal(args ...any) {
+	r.record(logger.SlogLevelFatal, fmt.Sprint(args...))
+}
+
+func (r *Recorder) Panic(args ...any) {
+	msg := fmt.Sprint(args...)
+	r.record(logger.SlogLevelPanic, msg)
+	panic(&logger.PanicValue{Message: msg, Attrs: r.attrs(nil)})
+}
+
+func (r *Recorder) Tracef(format string, args ...any) {
+	r.record(logger.SlogLevelTrace, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) Debugf(format string, args ...any) {
+	r.record(slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) Infof(format string, args ...any) {
+	r.record(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) Warnf(format string, args ...any) {
+	r.record(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) Warningf(format string, args ...any) {
+	r.record(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) Errorf(format string, args ...any) {
+	r.record(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+func (r *Recorder) Trace(args ...any) {
+	r.record(logger.SlogLevelTrace, fmt.Sprint(args...))
+}
+
+func (r *Recorder) Debug(args ...any) {
+	r.record(slog.LevelDebug, fmt.Sprint(args...))
+}
+
+func (r *Recorder) Info(args ...any) {
+	r.record(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+func (r *Recorder) Warn(args ...any) {
+	r.record(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (r *Recorder) Warning(args ...any) {
+	r.record(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (r *Recorder) Error(args ...any) {
+	r.record(slog.LevelError, fmt.Sprint(args...))
+}
+
+func (r *Recorder) ErrorfErr(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	r.record(slog.LevelError, err.Error())
+	return &logger.AttrError{Err: err, Attrs: r.attrs(nil)}
+}
+
+func (r *Recorder) WrapErr(err error, msg string, attrs ...any) error {
+	if err == nil {
+		return nil
+	}
+	var rec slog.Record
+	rec.Add(attrs...)
+	call := make([]slog.Attr, 0, rec.NumAttrs()+1)
+	rec.Attrs(func(a slog.Attr) bool {
+		call = append(call, a)
+		return true
+	})
+	r.record(slog.LevelError, msg, append(call, slog.Any("error", err))...)
+	return &logger.AttrError{Message: msg, Err: err, Attrs: r.attrs(call)}
+}
+
+func (r *Recorder) WithField(key string, value any) *Recorder {
+	return r.withAttrs([]slog.Attr{slog.Any(key, value)})
+}
+
+func (r *Recorder) WithFields(fields logger.Fields) *Recorder {
+	var rec slog.Record
+	rec.Add(fields.Args()...)
+	attrs := make([]slog.Attr, 0, rec.NumAttrs())
+	rec.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return r.withAttrs(attrs)
+}
+
+func (r *Recorder) WithError(err error) *Recorder {
+	return r.withAttrs([]slog.Attr{slog.Any("error", err)})
+}
+
+func (r *Recorder) withAttrs(attrs []slog.Attr) *Recorder {
+	if len(attrs) == 0 {
+		return r
+	}
+	frames := slices.Clone(r.frames)
+	last := &frames[len(frames)-1]
+	last.attrs = append(slices.Clip(last.attrs), attrs...)
+	return &Recorder{
+		store:  r.store,
+		frames: frames,
+	}
+}
+
+// record records an entry with attrs logged through one of the logging methods.
+// It must be called directly from them, so that the source points at their caller.
+func (r *Recorder) record(level slog.Level, msg string, attrs ...slog.Attr) {
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip [runtime.Callers, record, the logging method]
+	f, _ := runtime.CallersFrames(pcs[:]).Next()
+
+	r.add(Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Attrs:   r.attrs(attrs),
+		Source:  &slog.Source{Function: f.Function, File: f.File, Line: f.Line},
+	})
+}
+
+func (r *Recorder) add(e Entry) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.entries = append(r.store.entries, e)
+}
+
+// attrs returns the attributes of the recorder with attrs added to the innermost group.
+// Empty groups are omitted, like slog handlers do.
+func (r *Recorder) attrs(attrs []slog.Attr) []slog.Attr {
+	inner := attrs
+	for i := len(r.frames) - 1; i >= 0; i-- {
+		f := r.frames[i]
+		merged := make([]slog.Attr, 0, len(f.attrs)+len(inner))
+		merged = append(merged, f.attrs...)
+		if i == len(r.frames)-1 {
+			merged = append(merged, inner...)
+		} else if len(inner) > 0 {
+			merged = append(merged, slog.Attr{Key: r.frames[i+1].group, Value: slog.GroupValue(inner...)})
+		}
+		inner = merged
+	}
+	return inner
+}
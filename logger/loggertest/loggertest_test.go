@@ -0,0 +1,131 @@
+package loggertest_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+func TestRecorder_Logger(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	err := errors.New("boom")
+
+	_, file, line, _ := runtime.Caller(0)
+	rec.WithFields(logger.Fields{"b": 2, "a": 1}).WithError(err).Warnf("test %s", "message")
+	rec.Trace("trace")
+	rec.Fatal("fatal")
+
+	entries := rec.Entries()
+	c.Assert(entries, qt.HasLen, 3)
+	c.Assert(entries[0].Level, qt.Equals, slog.LevelWarn)
+	c.Assert(entries[0].Message, qt.Equals, "test message")
+	c.Assert(entries[0].Attrs, qt.DeepEquals, []slog.Attr{slog.Int("a", 1), slog.Int("b", 2), slog.Any("error", err)})
+	c.Assert(entries[0].Source.File, qt.Equals, file)
+	c.Assert(entries[0].Source.Line, qt.Equals, line+1)
+	c.Assert(entries[1].Level, qt.Equals, logger.SlogLevelTrace)
+	c.Assert(entries[2].Level, qt.Equals, logger.SlogLevelFatal)
+
+	c.Assert(rec.HasEntry(slog.LevelWarn, "message", slog.Int("a", 1), slog.Any("error", err)), qt.IsTrue)
+	c.Assert(rec.HasEntry(slog.LevelWarn, "message", slog.Int("a", 2)), qt.IsFalse)
+	c.Assert(rec.HasEntry(slog.LevelInfo, "message"), qt.IsFalse)
+	c.Assert(rec.FindEntries(logger.SlogLevelTrace, ""), qt.HasLen, 1)
+
+	rec.AssertEntry(t, logger.SlogLevelFatal, "fatal")
+	rec.AssertNoEntry(t, logger.SlogLevelPanic, "")
+
+	rec.Reset()
+	c.Assert(rec.Len(), qt.Equals, 0)
+}
+
+func TestRecorder_Panic(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	defer func() {
+		pv, ok := recover().(*logger.PanicValue)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(pv.Message, qt.Equals, "panic 1")
+		c.Assert(pv.Attrs, qt.DeepEquals, []slog.Attr{slog.String("k", "v")})
+		rec.AssertEntry(t, logger.SlogLevelPanic, "panic 1", slog.String("k", "v"))
+	}()
+	rec.WithField("k", "v").Panicf("panic %d", 1)
+}
+
+func TestRecorder_ErrorLogger(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	inner := rec.ErrorfErr("query %d", 1)
+	err := rec.WithField("k", "v").WrapErr(inner, "failed", "n", 2)
+
+	c.Assert(err, qt.ErrorMatches, "failed: query 1")
+	c.Assert(err, qt.ErrorIs, inner)
+	c.Assert(logger.AttrsFromError(err), qt.DeepEquals, []slog.Attr{slog.String("k", "v"), slog.Int("n", 2)})
+	rec.AssertEntry(t, slog.LevelError, "query 1")
+	rec.AssertEntry(t, slog.LevelError, "failed", slog.String("k", "v"), slog.Int("n", 2), slog.Any("error", inner))
+}
+
+func TestRecorder_Handler(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	l := slog.New(rec)
+
+	_, file, line, _ := runtime.Caller(0)
+	l.With("a", 1).WithGroup("g").With("b", 2).WithGroup("h").Info("test message", "c", 3)
+	l.WithGroup("empty").Info("no attrs")
+
+	entries := rec.Entries()
+	c.Assert(entries, qt.HasLen, 2)
+	c.Assert(entries[0].Source.File, qt.Equals, file)
+	c.Assert(entries[0].Source.Line, qt.Equals, line+1)
+	c.Assert(entries[0].Attrs, qt.DeepEquals, []slog.Attr{
+		slog.Int("a", 1),
+		slog.Group("g", slog.Int("b", 2), slog.Group("h", slog.Int("c", 3))),
+	})
+	c.Assert(entries[1].Attrs, qt.HasLen, 0)
+
+	rec.AssertEntry(t, slog.LevelInfo, "test", slog.Int("g.h.c", 3), slog.Int("g.b", 2))
+	c.Assert(rec.HasEntry(slog.LevelInfo, "test", slog.Int("g.c", 3)), qt.IsFalse)
+}
+
+func TestRecorder_Slog(t *testing.T) {
+	rec := loggertest.New()
+	sl := logger.NewSlog(slog.New(rec))
+	sl.WithField("user", "alice").Infof("logged %s", "in")
+
+	rec.AssertEntry(t, slog.LevelInfo, "logged in", slog.String("user", "alice"))
+}
+
+func TestRecorder_AssertEntry(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	rec.Info("test message")
+
+	ft := &fakeT{TB: t}
+	rec.AssertEntry(ft, slog.LevelWarn, "test")
+	c.Assert(ft.failure, qt.Equals, "no entry matching level=WARN msg=*test* attrs=[] found in:\n\tINFO \"test message\"")
+
+	ft = &fakeT{TB: t}
+	rec.AssertNoEntry(ft, slog.LevelInfo, "test")
+	c.Assert(ft.failure, qt.Equals, "unexpected entry matching level=INFO msg=*test* attrs=[] found in:\n\tINFO \"test message\"")
+}
+
+type fakeT struct {
+	testing.TB
+	failure string
+}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.failure = fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,383 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default keys of the fields written by LogrusHandler. They can be renamed with FieldMap.
+const (
+	FieldKeyMsg   = "msg"
+	FieldKeyLevel = "level"
+	FieldKeyTime  = "time"
+	FieldKeyFunc  = "func"
+	FieldKeyFile  = "file"
+)
+
+// FieldMap renames the default keys (FieldKeyMsg, FieldKeyLevel, etc.) written by LogrusHandler.
+// It is the counterpart of logrus.FieldMap:
+//
+//	logger.FieldMap{
+//		logger.FieldKeyTime: "@timestamp",
+//		logger.FieldKeyMsg:  "message",
+//	}
+type FieldMap map[string]string
+
+func (f FieldMap) resolve(key string) string {
+	if k, ok := f[key]; ok {
+		return k
+	}
+	return key
+}
+
+// LogrusHandlerOptions are options for LogrusHandler. They mirror the options of
+// logrus.TextFormatter and logrus.JSONFormatter. A zero LogrusHandlerOptions consists
+// entirely of default values, which produce the same output as logrus' default formatters.
+type LogrusHandlerOptions struct {
+	// Level reports the minimum record level that will be logged. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+
+	// AddSource adds the "func" and "file" fields, like logrus.Logger.ReportCaller.
+	AddSource bool
+
+	// DisableTimestamp disables writing the time field.
+	DisableTimestamp bool
+
+	// TimestampFormat is the layout used to format the time field. Defaults to time.RFC3339,
+	// like logrus outside of its colored terminal mode.
+	TimestampFormat string
+
+	// FullTimestamp mirrors logrus.TextFormatter.FullTimestamp so that formatter options map
+	// one-to-one. Logrus only uses it in its colored terminal mode, which LogrusHandler does not
+	// have, so it has no effect: the full timestamp is always written.
+	FullTimestamp bool
+
+	// DisableSorting disables sorting of the fields by key, so they are written in the order
+	// they were added. It only affects the text format: JSON objects are always sorted.
+	DisableSorting bool
+
+	// FieldMap renames the default keys.
+	FieldMap FieldMap
+
+	// DisableQuote disables quoting of the values in the text format.
+	DisableQuote bool
+
+	// QuoteEmptyFields quotes empty values in the text format.
+	QuoteEmptyFields bool
+
+	// DataKey nests all the fields under the given key in the JSON format.
+	DataKey string
+
+	// PrettyPrint indents the JSON output.
+	PrettyPrint bool
+}
+
+var _ slog.Handler = (*LogrusHandler)(nil)
+
+// LogrusHandler is a slog.Handler that writes records in the format of logrus.TextFormatter
+// (time="..." level=info msg="..." key=value) or logrus.JSONFormatter
+// ({"level":"info","msg":"...","time":"...","key":"value"}), so that log pipelines parsing logrus
+// output keep working after migrating to Slog.
+//
+// As logrus has no groups, attributes in groups are written as fields with dotted keys (group.key).
+// Levels are written with their logrus names (trace, debug, info, warning, error, fatal, panic).
+type LogrusHandler struct {
+	opts   LogrusHandlerOptions
+	isJSON bool
+	mu     *sync.Mutex
+	w      io.Writer
+	fields []flatField
+	prefix string
+}
+
+// flatField is an attribute whose key includes the names of its groups (group.key).
+type flatField struct {
+	key   string
+	value any
+}
+
+// NewLogrusTextHandler returns a LogrusHandler that writes to w in the format of logrus.TextFormatter
+// (with colors disabled). If opts is nil, the default options are used.
+func NewLogrusTextHandler(w io.Writer, opts *LogrusHandlerOptions) *LogrusHandler {
+	return newLogrusHandler(w, opts, false)
+}
+
+// NewLogrusJSONHandler returns a LogrusHandler that writes to w in the format of logrus.JSONFormatter.
+// If opts is nil, the default options are used.
+func NewLogrusJSONHandler(w io.Writer, opts *LogrusHandlerOptions) *LogrusHandler {
+	return newLogrusHandler(w, opts, true)
+}
+
+func newLogrusHandler(w io.Writer, opts *LogrusHandlerOptions, isJSON bool) *LogrusHandler {
+	h := &LogrusHandler{
+		isJSON: isJSON,
+		mu:     &sync.Mutex{},
+		w:      w,
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *LogrusHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// WithAttrs returns a new LogrusHandler that writes the given attributes with every record.
+func (h *LogrusHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	r := *h
+	r.fields = slices.Clip(r.fields)
+	for _, a := range attrs {
+		r.fields = appendFlatField(r.fields, r.prefix, a)
+	}
+	return &r
+}
+
+// WithGroup returns a new LogrusHandler that prefixes the keys of the subsequent attributes with name.
+func (h *LogrusHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	r := *h
+	r.prefix += name + "."
+	return &r
+}
+
+// Handle writes the record.
+func (h *LogrusHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make([]flatField, 0, len(h.fields)+r.NumAttrs())
+	fields = append(fields, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendFlatField(fields, h.prefix, a)
+		return true
+	})
+	fields = dedupFlatFields(fields)
+
+	var buf bytes.Buffer
+	if h.isJSON {
+		if err := h.formatJSON(&buf, r, fields); err != nil {
+			return err
+		}
+	} else {
+		h.formatText(&buf, r, fields)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *LogrusHandler) formatText(b *bytes.Buffer, r slog.Record, fields []flatField) {
+	fields = h.prefixFieldClashes(fields)
+	if !h.opts.DisableSorting {
+		slices.SortStableFunc(fields, func(x, y flatField) int {
+			return strings.Compare(x.key, y.key)
+		})
+	}
+
+	if !h.opts.DisableTimestamp && !r.Time.IsZero() {
+		h.appendKeyValue(b, h.opts.FieldMap.resolve(FieldKeyTime), r.Time.Format(h.timestampFormat()))
+	}
+	h.appendKeyValue(b, h.opts.FieldMap.resolve(FieldKeyLevel), logrusLevel(r.Level))
+	if r.Message != "" {
+		h.appendKeyValue(b, h.opts.FieldMap.resolve(FieldKeyMsg), r.Message)
+	}
+	if fn, file, ok := h.caller(r); ok {
+		h.appendKeyValue(b, h.opts.FieldMap.resolve(FieldKeyFunc), fn)
+		h.appendKeyValue(b, h.opts.FieldMap.resolve(FieldKeyFile), file)
+	}
+	for _, f := range fields {
+		h.appendKeyValue(b, f.key, f.value)
+	}
+	b.WriteByte('\n')
+}
+
+func (h *LogrusHandler) formatJSON(b *bytes.Buffer, r slog.Record, fields []flatField) error {
+	data := make(map[string]any, len(fields)+5)
+	for _, f := range fields {
+		if err, ok := f.value.(error); ok {
+			data[f.key] = err.Error()
+		} else {
+			data[f.key] = f.value
+		}
+	}
+	if h.opts.DataKey != "" {
+		nested := make(map[string]any, 5)
+		if len(data) > 0 {
+			nested[h.opts.DataKey] = data
+		}
+		data = nested
+	} else {
+		for _, key := range h.fixedKeys() {
+			if v, ok := data[key]; ok {
+				data["fields."+key] = v
+				delete(data, key)
+			}
+		}
+	}
+
+	if !h.opts.DisableTimestamp && !r.Time.IsZero() {
+		data[h.opts.FieldMap.resolve(FieldKeyTime)] = r.Time.Format(h.timestampFormat())
+	}
+	data[h.opts.FieldMap.resolve(FieldKeyLevel)] = logrusLevel(r.Level)
+	data[h.opts.FieldMap.resolve(FieldKeyMsg)] = r.Message
+	if fn, file, ok := h.caller(r); ok {
+		data[h.opts.FieldMap.resolve(FieldKeyFunc)] = fn
+		data[h.opts.FieldMap.resolve(FieldKeyFile)] = file
+	}
+
+	enc := json.NewEncoder(b)
+	if h.opts.PrettyPrint {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("logger: failed to marshal fields to JSON: %w", err)
+	}
+	return nil
+}
+
+// fixedKeys returns the keys written by the handler itself.
+func (h *LogrusHandler) fixedKeys() []string {
+	keys := []string{
+		h.opts.FieldMap.resolve(FieldKeyTime),
+		h.opts.FieldMap.resolve(FieldKeyMsg),
+		h.opts.FieldMap.resolve(FieldKeyLevel),
+	}
+	if h.opts.AddSource {
+		keys = append(keys, h.opts.FieldMap.resolve(FieldKeyFunc), h.opts.FieldMap.resolve(FieldKeyFile))
+	}
+	return keys
+}
+
+// prefixFieldClashes renames the fields that clash with the fixed keys to "fields.<key>", like logrus does.
+func (h *LogrusHandler) prefixFieldClashes(fields []flatField) []flatField {
+	fixed := h.fixedKeys()
+	for i, f := range fields {
+		if slices.Contains(fixed, f.key) {
+			fields[i].key = "fields." + f.key
+		}
+	}
+	return fields
+}
+
+func (h *LogrusHandler) timestampFormat() string {
+	if h.opts.TimestampFormat != "" {
+		return h.opts.TimestampFormat
+	}
+	return time.RFC3339
+}
+
+func (h *LogrusHandler) caller(r slog.Record) (fn, file string, ok bool) {
+	if !h.opts.AddSource || r.PC == 0 {
+		return "", "", false
+	}
+	f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+	return f.Function, fmt.Sprintf("%s:%d", f.File, f.Line), true
+}
+
+func (h *LogrusHandler) appendKeyValue(b *bytes.Buffer, key string, value any) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+
+	s, ok := value.(string)
+	if !ok {
+		s = fmt.Sprint(value)
+	}
+	if h.needsQuoting(s) {
+		b.WriteString(fmt.Sprintf("%q", s))
+	} else {
+		b.WriteString(s)
+	}
+}
+
+func (h *LogrusHandler) needsQuoting(text string) bool {
+	if h.opts.QuoteEmptyFields && text == "" {
+		return true
+	}
+	if h.opts.DisableQuote {
+		return false
+	}
+	return needsQuoting(text)
+}
+
+// needsQuoting reports whether text has to be quoted in key=value output, using the rules of logrus.
+func needsQuoting(text string) bool {
+	for _, ch := range text {
+		if (ch < 'a' || ch > 'z') && (ch < 'A' || ch > 'Z') && (ch < '0' || ch > '9') && !strings.ContainsRune("-._/@^+", ch) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendFlatField appends a to fields, flattening groups into dotted keys.
+func appendFlatField(fields []flatField, prefix string, a slog.Attr) []flatField {
+	if err := originalError(a.Value); err != nil {
+		return append(fields, flatField{key: prefix + a.Key, value: err})
+	}
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		return append(fields, flatField{key: prefix + a.Key, value: a.Value.Any()})
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, ga := range a.Value.Group() {
+		fields = appendFlatField(fields, prefix, ga)
+	}
+	return fields
+}
+
+// dedupFlatFields removes duplicate keys keeping the last value at the position of the first one,
+// as logrus fields are a map.
+func dedupFlatFields(fields []flatField) []flatField {
+	if len(fields) < 2 {
+		return fields
+	}
+	index := make(map[string]int, len(fields))
+	result := fields[:0]
+	for _, f := range fields {
+		if i, ok := index[f.key]; ok {
+			result[i].value = f.value
+			continue
+		}
+		index[f.key] = len(result)
+		result = append(result, f)
+	}
+	return result
+}
+
+// logrusLevel returns the logrus name of the level.
+func logrusLevel(level slog.Level) string {
+	_, name := Level(level).base()
+	if name == "WARN" {
+		return "warning"
+	}
+	return strings.ToLower(name)
+}
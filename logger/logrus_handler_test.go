@@ -0,0 +1,130 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+var testTime = time.Date(2023, 5, 6, 7, 8, 9, 123456789, time.UTC)
+
+func TestLogrusTextHandler(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(logger.NewLogrusTextHandler(&b, &logger.LogrusHandlerOptions{
+		Level:            logger.SlogLevelTrace,
+		DisableTimestamp: true,
+	})))
+
+	sl.WithField("b", "with space").WithField("a", 1).Infof("test %s", "message")
+	sl.WithError(errors.New("boom")).Warn("warning")
+	sl.WithField("msg", "clash").Trace("trace")
+	sl.WithGroup("g").With("k", "v", "k", "w").Error("group")
+	sl.Log(context.Background(), logger.SlogLevelFatal, "")
+
+	c.Assert(b.String(), qt.Equals, `level=info msg="test message" a=1 b="with space"
+level=warning msg=warning error=boom
+level=trace msg=trace fields.msg=clash
+level=error msg=group g.k=w
+level=fatal
+`)
+}
+
+func TestLogrusTextHandler_Options(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	h := logger.NewLogrusTextHandler(&b, &logger.LogrusHandlerOptions{
+		DisableSorting:   true,
+		FieldMap:         logger.FieldMap{logger.FieldKeyMsg: "message", logger.FieldKeyTime: "@timestamp"},
+		QuoteEmptyFields: true,
+	}).WithAttrs([]slog.Attr{slog.String("z", ""), slog.String("a", "x")})
+
+	r := slog.NewRecord(testTime, slog.LevelInfo, "test", 0)
+	c.Assert(h.Handle(context.Background(), r), qt.IsNil)
+
+	h = logger.NewLogrusTextHandler(&b, &logger.LogrusHandlerOptions{TimestampFormat: time.DateOnly})
+	c.Assert(h.Handle(context.Background(), r), qt.IsNil)
+
+	h = logger.NewLogrusTextHandler(&b, &logger.LogrusHandlerOptions{FullTimestamp: true})
+	c.Assert(h.Handle(context.Background(), r), qt.IsNil)
+
+	c.Assert(b.String(), qt.Equals, `@timestamp="2023-05-06T07:08:09Z" level=info message=test z="" a=x
+time=2023-05-06 level=info msg=test
+time="2023-05-06T07:08:09Z" level=info msg=test
+`)
+}
+
+func TestLogrusTextHandler_AddSource(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(logger.NewLogrusTextHandler(&b, &logger.LogrusHandlerOptions{
+		DisableTimestamp: true,
+		AddSource:        true,
+	})))
+
+	_, file, line, _ := runtime.Caller(0)
+	sl.Info("test")
+
+	c.Assert(b.String(), qt.Equals, "level=info msg=test "+
+		"func=github.com/go-extras/go-kit/logger_test.TestLogrusTextHandler_AddSource "+
+		"file=\""+file+":"+strconv.Itoa(line+1)+"\"\n")
+}
+
+func TestLogrusJSONHandler(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	h := logger.NewLogrusJSONHandler(&b, nil)
+
+	r := slog.NewRecord(testTime, slog.LevelWarn, "test message", 0)
+	r.AddAttrs(
+		slog.Any("error", errors.New("boom")),
+		slog.String("level", "clash"),
+		slog.Group("g", slog.Int("n", 1)),
+	)
+	c.Assert(h.WithAttrs([]slog.Attr{slog.Bool("b", true)}).Handle(context.Background(), r), qt.IsNil)
+	c.Assert(h.Enabled(context.Background(), slog.LevelDebug), qt.IsFalse)
+
+	c.Assert(b.String(), qt.Equals, `{"b":true,"error":"boom","fields.level":"clash","g.n":1,"level":"warning","msg":"test message","time":"2023-05-06T07:08:09Z"}`+"\n")
+}
+
+func TestLogrusJSONHandler_Options(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	h := logger.NewLogrusJSONHandler(&b, &logger.LogrusHandlerOptions{
+		DisableTimestamp: true,
+		DataKey:          "data",
+		FieldMap:         logger.FieldMap{logger.FieldKeyLevel: "severity"},
+		PrettyPrint:      true,
+	})
+
+	r := slog.NewRecord(testTime, logger.SlogLevelPanic, "test message", 0)
+	r.AddAttrs(slog.String("level", "no clash"))
+	c.Assert(h.Handle(context.Background(), r), qt.IsNil)
+
+	c.Assert(b.String(), qt.Equals, `{
+  "data": {
+    "level": "no clash"
+  },
+  "msg": "test message",
+  "severity": "panic"
+}
+`)
+
+	r = slog.NewRecord(testTime, slog.LevelInfo, "test message", 0)
+	r.AddAttrs(slog.Any("ch", make(chan int)))
+	c.Assert(h.Handle(context.Background(), r), qt.ErrorMatches, "logger: failed to marshal fields to JSON: .*")
+}
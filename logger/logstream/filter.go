@@ -0,0 +1,108 @@
+// Package logstream.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logstream
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/pubsub"
+)
+
+// Filter reports whether a record is passed to a subscriber (see Tail).
+type Filter func(r LogRecord) bool
+
+// Match reports whether r matches all the filters.
+func Match(r LogRecord, filters ...Filter) bool {
+	for _, f := range filters {
+		if !f(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// MinLevel returns a Filter matching the records at the given level or above.
+func MinLevel(level slog.Leveler) Filter {
+	return func(r LogRecord) bool {
+		return r.Level >= level.Level()
+	}
+}
+
+// MessageContains returns a Filter matching the records whose message contains substr.
+func MessageContains(substr string) Filter {
+	return func(r LogRecord) bool {
+		return strings.Contains(r.Message, substr)
+	}
+}
+
+// HasAttr returns a Filter matching the records with an attribute with the given key
+// (see LogRecord.Attr for the keys of attributes in groups).
+func HasAttr(key string) Filter {
+	return func(r LogRecord) bool {
+		_, ok := r.Attr(key)
+		return ok
+	}
+}
+
+// AttrEquals returns a Filter matching the records with an attribute with the given key
+// and value (see LogRecord.Attr for the keys of attributes in groups).
+func AttrEquals(key string, value any) Filter {
+	want := slog.AnyValue(value).Resolve()
+	return func(r LogRecord) bool {
+		v, ok := r.Attr(key)
+		return ok && v.Kind() == want.Kind() && reflect.DeepEqual(v.Any(), want.Any())
+	}
+}
+
+// Named returns a Filter matching the records of the loggers named prefix or
+// whose name starts with prefix followed by a dot (see logger.Slog.Named).
+// An empty prefix matches all the records.
+func Named(prefix string) Filter {
+	return func(r LogRecord) bool {
+		if prefix == "" {
+			return true
+		}
+		v, ok := r.Attr(logger.NameKey)
+		if !ok {
+			return false
+		}
+		name := v.String()
+		return name == prefix || strings.HasPrefix(name, prefix+".")
+	}
+}
+
+// AnyOf returns a Filter matching the records matching at least one of the filters.
+func AnyOf(filters ...Filter) Filter {
+	return func(r LogRecord) bool {
+		for _, f := range filters {
+			if f(r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Tail subscribes to pub and calls fn with the published records matching all the filters,
+// until ctx is done or fn returns false. It returns ctx.Err() in the former case and nil
+// in the latter.
+func Tail(ctx context.Context, pub *pubsub.Publisher[LogRecord], fn func(r LogRecord) bool, filters ...Filter) error {
+	sub := pub.Subscribe()
+	defer pub.Unsubscribe(sub)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-sub:
+			if Match(r, filters...) && !fn(r) {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Package logstream publishes log records to a pubsub.Publisher, so that admin endpoints
+// or debug UIs can subscribe to them and tail the logs live.
+//
+// Example usage:
+//
+//	pub := pubsub.NewPublisher[logstream.LogRecord](100)
+//	h := logger.NewMultiHandler(slog.NewJSONHandler(os.Stderr, nil), logstream.NewHandler(pub, nil))
+//	sl := logger.NewSlog(slog.New(h))
+//
+//	// in the handler of an admin endpoint
+//	err := logstream.Tail(r.Context(), pub, func(rec logstream.LogRecord) bool {
+//		return json.NewEncoder(w).Encode(rec) == nil
+//	}, logstream.MinLevel(slog.LevelWarn), logstream.Named("db"))
+//
+// It is a separate package, as package pubsub depends on package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logstream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/pubsub"
+)
+
+// LogRecord is a log record published by Handler.
+type LogRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Attrs are the attributes of the record, including the ones attached to the logger
+	// or handler it was logged with, resolved. Attributes added after slog.Logger.WithGroup
+	// are nested in groups.
+	Attrs []slog.Attr
+	// Source is the location the record was logged from. It is nil unless HandlerOptions.AddSource is set.
+	Source *slog.Source
+}
+
+// Attr returns the value of the attribute with the given key. Keys of attributes in groups
+// are dotted paths, e.g. "request.method".
+func (r LogRecord) Attr(key string) (slog.Value, bool) {
+	attrs := r.Attrs
+	path := strings.Split(key, ".")
+	for i, name := range path {
+		idx := slices.IndexFunc(attrs, func(a slog.Attr) bool { return a.Key == name })
+		if idx < 0 {
+			return slog.Value{}, false
+		}
+		v := attrs[idx].Value
+		if i == len(path)-1 {
+			return v, true
+		}
+		if v.Kind() != slog.KindGroup {
+			return slog.Value{}, false
+		}
+		attrs = v.Group()
+	}
+	return slog.Value{}, false
+}
+
+// MarshalJSON encodes the record as a JSON object with the time, level, msg, source (if any)
+// and attrs fields, the latter holding the attributes as an object. The values are encoded
+// like slog.JSONHandler does, except for errors, which are encoded as their message.
+func (r LogRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Time    time.Time      `json:"time"`
+		Level   string         `json:"level"`
+		Message string         `json:"msg"`
+		Source  *slog.Source   `json:"source,omitempty"`
+		Attrs   map[string]any `json:"attrs,omitempty"`
+	}{
+		Time:    r.Time,
+		Level:   logger.Level(r.Level).String(),
+		Message: r.Message,
+		Source:  r.Source,
+		Attrs:   attrsMap(r.Attrs),
+	})
+}
+
+// attrsMap returns attrs as a map, inlining the groups with an empty key.
+func attrsMap(attrs []slog.Attr) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(attrs))
+	addAttrs(m, attrs)
+	return m
+}
+
+func addAttrs(m map[string]any, attrs []slog.Attr) {
+	for _, a := range attrs {
+		switch {
+		case a.Value.Kind() == slog.KindGroup && a.Key == "":
+			addAttrs(m, a.Value.Group())
+		case a.Value.Kind() == slog.KindGroup:
+			m[a.Key] = attrsMap(a.Value.Group())
+		case a.Value.Kind() == slog.KindAny:
+			if err, ok := a.Value.Any().(error); ok {
+				m[a.Key] = err.Error()
+				continue
+			}
+			m[a.Key] = a.Value.Any()
+		default:
+			m[a.Key] = a.Value.Any()
+		}
+	}
+}
+
+// HandlerOptions are options for Handler.
+type HandlerOptions struct {
+	// Level reports the minimum record level that will be published. Defaults to slog.LevelInfo.
+	Level slog.Leveler
+
+	// AddSource adds the location of the logging calls to the records.
+	AddSource bool
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// Handler is a slog.Handler that publishes the records as LogRecord values to a pubsub.Publisher.
+//
+// The records are published with Publisher.TryPublish: they are dropped for the subscribers
+// that are too slow to receive them, and the handler never logs anything itself, so that
+// it cannot cause a logging loop, even if the logger of the publisher writes to it.
+// The number of dropped records is reported by Dropped. Handle never fails.
+//
+// Handlers derived with WithAttrs and WithGroup share the counter of the original handler.
+type Handler struct {
+	pub     *pubsub.Publisher[LogRecord]
+	opts    HandlerOptions
+	dropped *atomic.Uint64
+	frames  []frame
+}
+
+// frame holds the attributes added with WithAttrs within a group opened with WithGroup.
+// The first frame is the top level.
+type frame struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewHandler returns a Handler publishing to pub. If opts is nil, the default options are used.
+func NewHandler(pub *pubsub.Publisher[LogRecord], opts *HandlerOptions) *Handler {
+	h := &Handler{
+		pub:     pub,
+		dropped: &atomic.Uint64{},
+		frames:  []frame{{}},
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Dropped returns the number of records dropped so far for slow subscribers.
+// A record dropped for several subscribers is counted once per subscriber.
+func (h *Handler) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Enabled reports whether the handler publishes records at the given level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle publishes the record.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	rec := LogRecord{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   h.attrs(r),
+	}
+	if h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		rec.Source = &slog.Source{Function: f.Function, File: f.File, Line: f.Line}
+	}
+	if dropped := h.pub.TryPublish(rec); dropped > 0 {
+		h.dropped.Add(uint64(dropped))
+	}
+	return nil
+}
+
+// attrs returns the resolved attributes of the record, with the ones of the handler,
+// and with the groups opened with WithGroup turned into group attributes.
+// Empty groups are omitted, like slog handlers do.
+func (h *Handler) attrs(r slog.Record) []slog.Attr {
+	last := len(h.frames) - 1
+	attrs := make([]slog.Attr, 0, len(h.frames[last].attrs)+r.NumAttrs())
+	attrs = appendResolved(attrs, h.frames[last].attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendResolved(attrs, a)
+		return true
+	})
+	for i := last; i > 0; i-- {
+		parent := appendResolved(nil, h.frames[i-1].attrs...)
+		if len(attrs) == 0 {
+			attrs = parent
+			continue
+		}
+		attrs = append(parent, slog.Attr{Key: h.frames[i].group, Value: slog.GroupValue(attrs...)})
+	}
+	return attrs
+}
+
+// appendResolved appends attrs to dst with their values resolved, recursively,
+// so that the subscribers get the values as of the logging call.
+func appendResolved(dst []slog.Attr, attrs ...slog.Attr) []slog.Attr {
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			group := appendResolved(nil, a.Value.Group()...)
+			if len(group) == 0 {
+				continue
+			}
+			a.Value = slog.GroupValue(group...)
+		} else if a.Equal(slog.Attr{}) {
+			continue
+		}
+		dst = append(dst, a)
+	}
+	return dst
+}
+
+// WithAttrs returns a new Handler that publishes the given attributes with every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	r := *h
+	r.frames = slices.Clone(h.frames)
+	last := &r.frames[len(r.frames)-1]
+	last.attrs = append(slices.Clip(last.attrs), attrs...)
+	return &r
+}
+
+// WithGroup returns a new Handler that nests the subsequent attributes in the group name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	r := *h
+	r.frames = append(slices.Clip(h.frames), frame{group: name})
+	return &r
+}
@@ -0,0 +1,244 @@
+package logstream_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+	"github.com/go-extras/go-kit/logger/logstream"
+	"github.com/go-extras/go-kit/pubsub"
+)
+
+func TestHandler(t *testing.T) {
+	c := qt.New(t)
+
+	pub := pubsub.NewPublisher[logstream.LogRecord](10)
+	sub := pub.Subscribe()
+	defer pub.Unsubscribe(sub)
+
+	l := slog.New(logstream.NewHandler(pub, &logstream.HandlerOptions{Level: slog.LevelDebug, AddSource: true}))
+	_, file, line, _ := runtime.Caller(0)
+	l.With("a", 1).WithGroup("req").With("id", 2).Debug("m", "method", "GET", slog.Group("empty"))
+	l.WithGroup("req").Info("n")
+	l.Debug("trace", "k", slog.GroupValue())
+
+	rec := <-sub
+	c.Assert(rec.Level, qt.Equals, slog.LevelDebug)
+	c.Assert(rec.Message, qt.Equals, "m")
+	c.Assert(rec.Time.IsZero(), qt.IsFalse)
+	c.Assert(rec.Attrs, qt.DeepEquals, []slog.Attr{
+		slog.Int("a", 1),
+		slog.Group("req", slog.Int("id", 2), slog.String("method", "GET")),
+	})
+	c.Assert(rec.Source.File, qt.Equals, file)
+	c.Assert(rec.Source.Line, qt.Equals, line+1)
+
+	v, ok := rec.Attr("req.method")
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(v.String(), qt.Equals, "GET")
+	_, ok = rec.Attr("a.b")
+	c.Assert(ok, qt.IsFalse)
+
+	rec = <-sub
+	c.Assert(rec.Message, qt.Equals, "n")
+	c.Assert(rec.Attrs, qt.HasLen, 0)
+
+	rec = <-sub
+	c.Assert(rec.Message, qt.Equals, "trace")
+	c.Assert(rec.Attrs, qt.HasLen, 0)
+}
+
+func TestHandler_Level(t *testing.T) {
+	c := qt.New(t)
+
+	h := logstream.NewHandler(pubsub.NewPublisher[logstream.LogRecord](1), nil)
+	c.Assert(h.Enabled(context.Background(), slog.LevelDebug), qt.IsFalse)
+	c.Assert(h.Enabled(context.Background(), slog.LevelInfo), qt.IsTrue)
+}
+
+func TestHandler_Dropped(t *testing.T) {
+	c := qt.New(t)
+
+	// the publisher logs through the recorder when Publish drops messages
+	rec := loggertest.New()
+	pub := pubsub.NewPublisher[logstream.LogRecord](1, pubsub.WithLogger[logstream.LogRecord](rec))
+	sub1 := pub.Subscribe()
+	defer pub.Unsubscribe(sub1)
+	sub2 := pub.Subscribe()
+	defer pub.Unsubscribe(sub2)
+
+	h := logstream.NewHandler(pub, nil)
+	l := slog.New(h.WithAttrs([]slog.Attr{slog.Int("a", 1)}))
+	l.Info("first")
+	<-sub1
+	l.Info("second")
+	l.Info("third")
+
+	c.Assert(h.Dropped(), qt.Equals, uint64(3))
+	c.Assert(rec.Len(), qt.Equals, 0)
+	c.Assert((<-sub1).Message, qt.Equals, "second")
+	c.Assert((<-sub2).Message, qt.Equals, "first")
+}
+
+func TestHandler_Slog(t *testing.T) {
+	c := qt.New(t)
+
+	pub := pubsub.NewPublisher[logstream.LogRecord](1)
+	sub := pub.Subscribe()
+	defer pub.Unsubscribe(sub)
+
+	sl := logger.NewSlog(slog.New(logstream.NewHandler(pub, nil)))
+	sl.Named("db").WithField("k", "v").WithError(errors.New("boom")).Error("query failed")
+
+	rec := <-sub
+	c.Assert(logstream.Match(rec, logstream.Named("db"), logstream.AttrEquals("k", "v")), qt.IsTrue)
+	v, ok := rec.Attr("error.msg")
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(v.String(), qt.Equals, "boom")
+}
+
+func TestHandler_SlogNamedGroup(t *testing.T) {
+	c := qt.New(t)
+
+	pub := pubsub.NewPublisher[logstream.LogRecord](2)
+	sub := pub.Subscribe()
+	defer pub.Unsubscribe(sub)
+
+	db := logger.NewSlog(slog.New(logstream.NewHandler(pub, nil))).Named("db")
+	db.Logger = db.WithGroup("req")
+	db.WithField("k", 1).Info("grouped")
+	db.Named("pool").Info("renamed")
+
+	rec := <-sub
+	c.Assert(logstream.Match(rec, logstream.Named("db"), logstream.AttrEquals("req.k", 1)), qt.IsTrue)
+	c.Assert(rec.Attrs, qt.DeepEquals, []slog.Attr{
+		slog.String(logger.NameKey, "db"),
+		slog.Group("req", slog.Int("k", 1)),
+	})
+	rec = <-sub
+	c.Assert(logstream.Named("db.pool")(rec), qt.IsTrue)
+	c.Assert(rec.Attrs, qt.DeepEquals, []slog.Attr{slog.String(logger.NameKey, "db.pool")})
+}
+
+func TestLogRecord_MarshalJSON(t *testing.T) {
+	c := qt.New(t)
+
+	rec := logstream.LogRecord{
+		Time:    time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC),
+		Level:   logger.SlogLevelTrace,
+		Message: "m",
+		Attrs: []slog.Attr{
+			slog.Int("n", 1),
+			slog.Any("err", errors.New("boom")),
+			slog.Group("req", slog.String("method", "GET")),
+			slog.Group("", slog.Bool("inline", true)),
+		},
+	}
+	b, err := json.Marshal(rec)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"time":"2023-05-06T07:08:09Z","level":"TRACE","msg":"m","attrs":{"err":"boom","inline":true,"n":1,"req":{"method":"GET"}}}`)
+
+	b, err = json.Marshal(logstream.LogRecord{Time: rec.Time, Level: slog.LevelInfo, Message: "m"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"time":"2023-05-06T07:08:09Z","level":"INFO","msg":"m"}`)
+}
+
+func TestFilters(t *testing.T) {
+	c := qt.New(t)
+
+	rec := logstream.LogRecord{
+		Level:   slog.LevelWarn,
+		Message: "connection lost",
+		Attrs: []slog.Attr{
+			slog.String(logger.NameKey, "db.pool"),
+			slog.Group("req", slog.Int("id", 42)),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter logstream.Filter
+		want   bool
+	}{
+		{"MinLevel below", logstream.MinLevel(slog.LevelInfo), true},
+		{"MinLevel above", logstream.MinLevel(slog.LevelError), false},
+		{"MessageContains", logstream.MessageContains("lost"), true},
+		{"MessageContains missing", logstream.MessageContains("found"), false},
+		{"HasAttr", logstream.HasAttr("req.id"), true},
+		{"HasAttr missing", logstream.HasAttr("req.method"), false},
+		{"AttrEquals", logstream.AttrEquals("req.id", 42), true},
+		{"AttrEquals other value", logstream.AttrEquals("req.id", 43), false},
+		{"AttrEquals other kind", logstream.AttrEquals("req.id", "42"), false},
+		{"Named", logstream.Named("db"), true},
+		{"Named exact", logstream.Named("db.pool"), true},
+		{"Named partial", logstream.Named("d"), false},
+		{"Named empty", logstream.Named(""), true},
+		{"AnyOf", logstream.AnyOf(logstream.Named("http"), logstream.MinLevel(slog.LevelWarn)), true},
+		{"AnyOf none", logstream.AnyOf(logstream.Named("http"), logstream.MinLevel(slog.LevelError)), false},
+	}
+	for _, tt := range tests {
+		c.Check(tt.filter(rec), qt.Equals, tt.want, qt.Commentf("%s", tt.name))
+	}
+
+	c.Assert(logstream.Named("db")(logstream.LogRecord{}), qt.IsFalse)
+}
+
+func TestTail(t *testing.T) {
+	c := qt.New(t)
+
+	pub := pubsub.NewPublisher[logstream.LogRecord](10)
+	l := slog.New(logstream.NewHandler(pub, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subscribed := make(chan struct{})
+	done := make(chan error)
+	var got []string
+	go func() {
+		done <- logstream.Tail(ctx, pub, func(r logstream.LogRecord) bool {
+			if r.Message == "ready" {
+				close(subscribed)
+				return true
+			}
+			got = append(got, r.Message)
+			return len(got) < 2
+		}, logstream.AnyOf(logstream.MessageContains("ready"), logstream.MinLevel(slog.LevelWarn)))
+	}()
+
+	// Tail subscribes asynchronously: log until the subscription is effective
+	func() {
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			l.Info("ready")
+			select {
+			case <-subscribed:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	l.Info("skipped")
+	l.Warn("first")
+	l.Error("second")
+	l.Error("third")
+
+	c.Assert(<-done, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, []string{"first", "second"})
+
+	go func() {
+		done <- logstream.Tail(ctx, pub, func(logstream.LogRecord) bool { return true })
+	}()
+	cancel()
+	c.Assert(<-done, qt.ErrorIs, context.Canceled)
+}
@@ -0,0 +1,105 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+var (
+	_ slog.Handler = (*MultiHandler)(nil)
+	_ Flusher      = (*MultiHandler)(nil)
+)
+
+// MultiHandler is a slog.Handler that forwards each record to several handlers (sinks).
+//
+// Each sink only receives the records it is enabled for, so sinks can have their own minimum
+// levels (see NewLevelHandler). Sinks are isolated from each other: a sink returning an error
+// or panicking does not prevent the record from reaching the other sinks. The errors are joined
+// and returned by Handle.
+//
+// Example usage (JSON to a file and human-readable text to stderr):
+//
+//	h := logger.NewMultiHandler(
+//		slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug}),
+//		logger.NewLevelHandler(slog.LevelWarn, slog.NewTextHandler(os.Stderr, nil)),
+//	)
+//	l := logger.NewSlog(slog.New(h))
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+// NewMultiHandler returns a MultiHandler that forwards records to handlers.
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &MultiHandler{
+		handlers: handlers,
+	}
+}
+
+// Enabled reports whether any of the sinks handles records at the given level.
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, sink := range h.handlers {
+		if sink.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle forwards the record to all the sinks enabled for its level.
+func (h *MultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, sink := range h.handlers {
+		if !sink.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handleIsolated(ctx, sink, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new MultiHandler whose sinks have the given attributes.
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(h.handlers))
+	for _, sink := range h.handlers {
+		handlers = append(handlers, sink.WithAttrs(attrs))
+	}
+	return NewMultiHandler(handlers...)
+}
+
+// WithGroup returns a new MultiHandler whose sinks have the given group.
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(h.handlers))
+	for _, sink := range h.handlers {
+		handlers = append(handlers, sink.WithGroup(name))
+	}
+	return NewMultiHandler(handlers...)
+}
+
+// Flush flushes all the sinks implementing Flusher.
+func (h *MultiHandler) Flush(ctx context.Context) error {
+	var errs []error
+	for _, sink := range h.handlers {
+		if err := flushHandler(ctx, sink); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// handleIsolated passes r to h, turning a panic into an error.
+func handleIsolated(ctx context.Context, h slog.Handler, r slog.Record) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("logger: handler panicked: %v", p)
+		}
+	}()
+	return h.Handle(ctx, r)
+}
@@ -0,0 +1,81 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+// failingHandler is a handler that fails or panics on every record.
+type failingHandler struct {
+	slog.Handler
+	panics bool
+}
+
+func (h failingHandler) Handle(context.Context, slog.Record) error {
+	if h.panics {
+		panic("boom")
+	}
+	return errors.New("sink failed")
+}
+
+func (h failingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h failingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestMultiHandler(t *testing.T) {
+	c := qt.New(t)
+
+	all := loggertest.New()
+	warn := loggertest.New()
+	h := logger.NewMultiHandler(
+		failingHandler{Handler: all},
+		all,
+		failingHandler{Handler: all, panics: true},
+		logger.NewLevelHandler(slog.LevelWarn, warn),
+	)
+
+	l := slog.New(h).With("a", 1).WithGroup("g")
+	l.Info("info", "k", "v")
+	l.Warn("warn")
+
+	all.AssertEntry(t, slog.LevelInfo, "info", slog.Int("a", 1), slog.String("g.k", "v"))
+	all.AssertEntry(t, slog.LevelWarn, "warn", slog.Int("a", 1))
+	warn.AssertNoEntry(t, slog.LevelInfo, "info")
+	warn.AssertEntry(t, slog.LevelWarn, "warn", slog.Int("a", 1))
+
+	err := h.Handle(context.Background(), slog.NewRecord(testTime, slog.LevelInfo, "direct", 0))
+	c.Assert(err, qt.ErrorMatches, "sink failed\nlogger: handler panicked: boom")
+	all.AssertEntry(t, slog.LevelInfo, "direct")
+}
+
+func TestMultiHandler_Enabled(t *testing.T) {
+	c := qt.New(t)
+
+	h := logger.NewMultiHandler(
+		logger.NewLevelHandler(slog.LevelWarn, loggertest.New()),
+		logger.NewLevelHandler(slog.LevelError, loggertest.New()),
+	)
+	c.Assert(h.Enabled(context.Background(), slog.LevelInfo), qt.IsFalse)
+	c.Assert(h.Enabled(context.Background(), slog.LevelWarn), qt.IsTrue)
+	c.Assert(logger.NewMultiHandler().Enabled(context.Background(), slog.LevelError), qt.IsFalse)
+}
+
+func TestMultiHandler_Flush(t *testing.T) {
+	c := qt.New(t)
+
+	var flushed int
+	h := logger.NewMultiHandler(
+		flushHandler{Handler: loggertest.New(), flushed: &flushed},
+		loggertest.New(),
+		logger.NewLevelHandler(slog.LevelWarn, flushHandler{Handler: loggertest.New(), flushed: &flushed}),
+	)
+	c.Assert(h.Flush(context.Background()), qt.IsNil)
+	c.Assert(flushed, qt.Equals, 2)
+}
@@ -0,0 +1,130 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// NameKey is the key of the attribute holding the name of the loggers built with Slog.Named.
+const NameKey = "logger"
+
+// Named returns a copy of the logger named after the name of the logger and name, joined
+// with a dot, e.g. sl.Named("db").Named("pool") is named "db.pool".
+// The records of the returned logger have a top-level NameKey attribute with the name, which replaces
+// the name of the logger rather than being added to it, and the records are only handled
+// if their level is at or above the level of the name in the level registry of the logger
+// (DefaultLevels unless set with WithLevels). See LevelRegistry for how the levels are looked up.
+func (s *Slog) Named(name string) *Slog {
+	if s.name != "" {
+		name = s.name + "." + name
+	}
+	r := s.clone()
+	r.name = name
+	r.Logger = slog.New(r.namedHandler(s.Handler()))
+	return r
+}
+
+// Name returns the name of the logger set with Named, if any.
+func (s *Slog) Name() string {
+	return s.name
+}
+
+// WithLevels returns a copy of the logger that uses the levels of registry for itself
+// and for the loggers built from it with Named. An unnamed logger gets the default level
+// of the registry, i.e. the level of the empty prefix.
+func (s *Slog) WithLevels(registry *LevelRegistry) *Slog {
+	r := s.clone()
+	r.levels = registry
+	r.Logger = slog.New(r.namedHandler(s.Handler()))
+	return r
+}
+
+// namedHandler returns h gated by the level of the name of the logger, with the name attached.
+// The gate is kept outermost, so that a named logger replaces the gate and the name of its parent
+// instead of adding to them; otherwise "db.pool" could not be more verbose than "db".
+func (s *Slog) namedHandler(h slog.Handler) slog.Handler {
+	var ops []handlerOp
+	if nh, ok := h.(*namedHandler); ok {
+		h, ops = nh.unnamed, nh.ops
+	}
+	levels := s.levels
+	if levels == nil {
+		levels = defaultLevels
+	}
+	// the name is attached before the attributes and groups added since the handler
+	// was named, so that it is a top-level attribute
+	next := h
+	if s.name != "" {
+		next = next.WithAttrs([]slog.Attr{slog.String(NameKey, s.name)})
+	}
+	for _, op := range ops {
+		next = op.apply(next)
+	}
+	return &namedHandler{name: s.name, level: levels.leveler(s.name), next: next, unnamed: h, ops: ops}
+}
+
+// namedHandler is the handler of the loggers built with Slog.Named.
+type namedHandler struct {
+	name  string
+	level slog.Leveler
+	next  slog.Handler
+
+	// unnamed is the handler the logger was named from, and ops are the attributes
+	// and groups added since, so that the name can be replaced.
+	unnamed slog.Handler
+	ops     []handlerOp
+}
+
+// handlerOp is an attribute list added with WithAttrs, or a group opened with WithGroup.
+type handlerOp struct {
+	group string
+	attrs []slog.Attr
+}
+
+func (op handlerOp) apply(h slog.Handler) slog.Handler {
+	if op.attrs != nil {
+		return h.WithAttrs(op.attrs)
+	}
+	return h.WithGroup(op.group)
+}
+
+func (h *namedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.next.Enabled(ctx, level)
+}
+
+func (h *namedHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *namedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(h.next.WithAttrs(attrs), handlerOp{attrs: attrs})
+}
+
+func (h *namedHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(h.next.WithGroup(name), handlerOp{group: name})
+}
+
+func (h *namedHandler) with(next slog.Handler, op handlerOp) *namedHandler {
+	return &namedHandler{
+		name:    h.name,
+		level:   h.level,
+		next:    next,
+		unnamed: h.unnamed,
+		ops:     append(slices.Clip(h.ops), op),
+	}
+}
+
+func (h *namedHandler) Flush(ctx context.Context) error {
+	return flushHandler(ctx, h.next)
+}
@@ -0,0 +1,75 @@
+package logger_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+func TestSlog_Named(t *testing.T) {
+	c := qt.New(t)
+
+	levels := logger.NewLevelRegistry()
+	c.Assert(levels.Parse("info,db=warn,db.pool=debug"), qt.IsNil)
+
+	rec := loggertest.New()
+	sl := logger.NewSlog(slog.New(logger.NewLevelHandler(logger.SlogLevelTrace, rec))).WithLevels(levels)
+	db := sl.Named("db").WithField("a", 1)
+	pool := db.Named("pool")
+	c.Assert(db.Name(), qt.Equals, "db")
+	c.Assert(pool.Name(), qt.Equals, "db.pool")
+
+	sl.Debug("root debug")
+	db.Info("db info")
+	db.Warn("db warn")
+	pool.Debug("pool debug")
+	pool.Trace("pool trace")
+
+	rec.AssertNoEntry(t, slog.LevelDebug, "root debug")
+	rec.AssertNoEntry(t, slog.LevelInfo, "db info")
+	rec.AssertEntry(t, slog.LevelWarn, "db warn", slog.Int("a", 1), slog.String(logger.NameKey, "db"))
+	rec.AssertEntry(t, slog.LevelDebug, "pool debug", slog.Int("a", 1), slog.String(logger.NameKey, "db.pool"))
+	rec.AssertNoEntry(t, logger.SlogLevelTrace, "pool trace")
+
+	// levels are looked up on every call
+	levels.LevelVar("db").Set(slog.LevelInfo)
+	db.Info("db info again")
+	rec.AssertEntry(t, slog.LevelInfo, "db info again")
+}
+
+func TestSlog_Named_DefaultLevels(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	fl := logger.NewFieldsSlog(slog.New(rec)).Named("named_test")
+	fl.Debug("debug")
+	rec.AssertEntry(t, slog.LevelDebug, "debug", slog.String(logger.NameKey, "named_test"))
+
+	logger.DefaultLevels().Set("named_test", slog.LevelInfo)
+	defer logger.DefaultLevels().Unset("named_test")
+	fl.Debug("hidden")
+	c.Assert(rec.Len(), qt.Equals, 1)
+}
+
+func TestSlog_Named_Group(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{ReplaceAttr: dropTime})))
+	req := sl.Named("db")
+	req.Logger = req.WithGroup("req")
+	req = req.WithField("k", 1)
+	req.Info("grouped")
+	req.Named("pool").Info("renamed")
+	req.Named("pool").WithLevels(logger.NewLevelRegistry()).Info("regated")
+
+	c.Assert(b.String(), qt.Equals, `level=INFO msg=grouped logger=db req.k=1
+level=INFO msg=renamed logger=db.pool req.k=1
+level=INFO msg=regated logger=db.pool req.k=1
+`)
+}
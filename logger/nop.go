@@ -0,0 +1,82 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import "fmt"
+
+var (
+	_ PrimitiveLogger               = Nop{}
+	_ BasicLogger                   = Nop{}
+	_ LevelLogger                   = Nop{}
+	_ TraceLogger                   = Nop{}
+	_ FieldLogger[Fields, Nop]      = Nop{}
+	_ TraceFieldLogger[Fields, Nop] = Nop{}
+	_ ErrorLogger                   = Nop{}
+)
+
+// Nop is a logger that implements all the interfaces of this package and discards everything.
+// Note that Fatal, Fatalf, Panic and Panicf do nothing either: they neither exit nor panic.
+type Nop struct{}
+
+func (Nop) Printf(string, ...any) {}
+
+func (Nop) Print(...any) {}
+
+func (Nop) Fatalf(string, ...any) {}
+
+func (Nop) Panicf(string, ...any) {}
+
+func (Nop) Fatal(...any) {}
+
+func (Nop) Panic(...any) {}
+
+func (Nop) Tracef(string, ...any) {}
+
+func (Nop) Debugf(string, ...any) {}
+
+func (Nop) Infof(string, ...any) {}
+
+func (Nop) Warnf(string, ...any) {}
+
+func (Nop) Warningf(string, ...any) {}
+
+func (Nop) Errorf(string, ...any) {}
+
+func (Nop) Trace(...any) {}
+
+func (Nop) Debug(...any) {}
+
+func (Nop) Info(...any) {}
+
+func (Nop) Warn(...any) {}
+
+func (Nop) Warning(...any) {}
+
+func (Nop) Error(...any) {}
+
+func (n Nop) WithField(string, any) Nop {
+	return n
+}
+
+func (n Nop) WithFields(Fields) Nop {
+	return n
+}
+
+func (n Nop) WithError(error) Nop {
+	return n
+}
+
+// ErrorfErr returns the formatted error without logging it.
+func (Nop) ErrorfErr(format string, args ...any) error {
+	return fmt.Errorf(format, args...)
+}
+
+// WrapErr returns err wrapped in an *AttrError carrying attrs without logging it, or nil if err is nil.
+func (Nop) WrapErr(err error, msg string, attrs ...any) error {
+	if err == nil {
+		return nil
+	}
+	return &AttrError{Message: msg, Err: err, Attrs: argsToAttrs(attrs)}
+}
@@ -0,0 +1,29 @@
+package logger_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func TestNop(t *testing.T) {
+	c := qt.New(t)
+
+	var l logger.TraceFieldLogger[logger.Fields, logger.Nop] = logger.Nop{}
+	c.Assert(func() {
+		l.WithField("k", "v").WithFields(logger.Fields{"a": 1}).WithError(errors.New("boom")).Infof("test %s", "message")
+		l.Fatal("fatal")
+		l.Panicf("panic %d", 1)
+		l.Trace("trace")
+	}, qt.Not(qt.PanicMatches), ".*")
+
+	err := logger.Nop{}.WrapErr(errors.New("boom"), "failed", "k", "v")
+	c.Assert(err, qt.ErrorMatches, "failed: boom")
+	c.Assert(logger.AttrsFromError(err), qt.DeepEquals, []slog.Attr{slog.String("k", "v")})
+	c.Assert(logger.Nop{}.ErrorfErr("test %d", 1), qt.ErrorMatches, "test 1")
+	c.Assert(logger.Nop{}.WrapErr(nil, "failed"), qt.IsNil)
+}
@@ -0,0 +1,23 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"log/slog"
+)
+
+// PanicValue is the value Panic and Panicf panic with.
+// It carries the logged message along with the fields attached to the logger
+// (see Slog.WithField, Slog.WithFields and Slog.WithError), so that the code
+// recovering from the panic has the same context as the log record.
+type PanicValue struct {
+	Message string
+	Attrs   []slog.Attr
+}
+
+// Error returns the message, so that PanicValue can be treated as an error after recover.
+func (p *PanicValue) Error() string {
+	return p.Message
+}
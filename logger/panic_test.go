@@ -0,0 +1,49 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func TestSlog_Panic(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(&b, nil)))
+	err := errors.New("test error")
+
+	defer func() {
+		r := recover()
+		pv, ok := r.(*logger.PanicValue)
+		c.Assert(ok, qt.IsTrue, qt.Commentf("unexpected panic value: %#v", r))
+		c.Assert(pv.Message, qt.Equals, "test message 1")
+		c.Assert(pv.Error(), qt.Equals, "test message 1")
+		c.Assert(pv.Attrs, qt.HasLen, 3)
+		c.Assert(pv.Attrs[:2], qt.DeepEquals, []slog.Attr{
+			slog.String("k", "v"),
+			slog.Int("n", 1),
+		})
+		c.Assert(pv.Attrs[2].Key, qt.Equals, "error")
+		c.Assert(pv.Attrs[2].Value.Any(), qt.ErrorIs, err)
+		c.Assert(b.String(), qt.Contains, "msg=\"test message 1\" k=v n=1 error.msg=\"test error\" error.type=*errors.errorString\n")
+	}()
+
+	sl.WithField("k", "v").WithFields(logger.SlogFields("n", 1)).WithError(err).Panicf("test message %d", 1)
+}
+
+func TestPanic(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&b, nil)))
+
+	c.Assert(func() { logger.Panic("test message") }, qt.PanicMatches, "test message")
+	c.Assert(b.String(), qt.Contains, "msg=\"test message\"\n")
+}
@@ -0,0 +1,273 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitOptions are options for RateLimitHandler.
+type RateLimitOptions struct {
+	// Interval is the rate limiting window. Defaults to one second.
+	Interval time.Duration
+
+	// Burst is the number of records with the same level and message passed through
+	// during each interval. Defaults to 1.
+	Burst int
+
+	// OnError is called by the background goroutine with the errors returned by the wrapped
+	// handler for the summaries. Defaults to printing the error to stderr.
+	OnError func(err error)
+
+	// Now returns the current time. Defaults to time.Now. It is mostly useful in tests.
+	Now func() time.Time
+}
+
+var (
+	_ slog.Handler = (*RateLimitHandler)(nil)
+	_ Flusher      = (*RateLimitHandler)(nil)
+)
+
+// RateLimitHandler is a slog.Handler wrapper that passes through at most RateLimitOptions.Burst
+// records with the same level and message per interval and suppresses the rest.
+// Unlike SamplingHandler, it reports what it suppressed: once the interval of a message
+// is over, it emits a summary record at the level of the suppressed records:
+//
+//	level=WARN msg="suppressed 41 messages" message="dropping message because subscriber is too slow"
+//
+// A background goroutine emits the summaries at most one interval after the interval of
+// their message is over, even if no other record is logged; a later record emits them sooner.
+// Flush emits all the pending summaries. The summaries are passed to the wrapped handler
+// without the attributes and groups added with WithAttrs and WithGroup.
+//
+// Programs should call Close before exiting to emit the pending summaries and stop
+// the background goroutine.
+//
+// Handlers derived with WithAttrs and WithGroup share the counters of the original handler.
+type RateLimitHandler struct {
+	next  slog.Handler
+	state *rateLimitState
+}
+
+type rateLimitWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+type rateLimitState struct {
+	opts      RateLimitOptions
+	root      slog.Handler
+	mu        sync.Mutex
+	lastSweep time.Time
+	windows   map[samplingKey]*rateLimitWindow
+	closed    bool
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// NewRateLimitHandler returns a RateLimitHandler that passes rate limited records to next
+// and starts its background goroutine.
+func NewRateLimitHandler(next slog.Handler, opts RateLimitOptions) *RateLimitHandler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = 1
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(err error) {
+			fmt.Fprintln(os.Stderr, "logger: rate limit handler failed to handle summary:", err)
+		}
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	state := &rateLimitState{
+		opts:      opts,
+		root:      next,
+		lastSweep: opts.Now(),
+		windows:   make(map[samplingKey]*rateLimitWindow),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go state.run()
+
+	return &RateLimitHandler{
+		next:  next,
+		state: state,
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *RateLimitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle emits the pending summaries and passes the record to the wrapped handler
+// unless it exceeds the rate limit.
+func (h *RateLimitHandler) Handle(ctx context.Context, r slog.Record) error {
+	allowed, summaries := h.state.allow(r.Level, r.Message)
+	err := h.state.emit(ctx, summaries)
+	if allowed {
+		err = errors.Join(err, h.next.Handle(ctx, r))
+	}
+	return err
+}
+
+// WithAttrs returns a new RateLimitHandler whose wrapped handler has the given attributes.
+func (h *RateLimitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RateLimitHandler{next: h.next.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a new RateLimitHandler whose wrapped handler has the given group.
+func (h *RateLimitHandler) WithGroup(name string) slog.Handler {
+	return &RateLimitHandler{next: h.next.WithGroup(name), state: h.state}
+}
+
+// Flush emits the summaries of all the suppressed records, including the ones whose interval
+// is not over yet, and flushes the wrapped handler if it implements Flusher.
+func (h *RateLimitHandler) Flush(ctx context.Context) error {
+	summaries := h.state.sweep()
+	return errors.Join(h.state.emit(ctx, summaries), flushHandler(ctx, h.next))
+}
+
+// Close stops the background goroutine and flushes the handler (see Flush). It returns the error
+// of the context if it is done before the goroutine stops. Closing an already closed handler
+// only flushes it. The handler keeps rate limiting records after Close, but their summaries are
+// only emitted by later records and Flush.
+func (h *RateLimitHandler) Close(ctx context.Context) error {
+	s := h.state
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return h.Flush(ctx)
+}
+
+// run emits the summaries of the expired windows every interval until the handler is closed.
+func (s *rateLimitState) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.emit(context.Background(), s.expire()); err != nil {
+				s.opts.OnError(err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *rateLimitState) allow(level slog.Level, msg string) (bool, []slog.Record) {
+	now := s.opts.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []slog.Record
+	if now.Sub(s.lastSweep) >= s.opts.Interval {
+		summaries = s.sweepLocked(now, now.Add(-s.opts.Interval))
+	}
+
+	key := samplingKey{level: level, msg: msg}
+	w, ok := s.windows[key]
+	if !ok {
+		w = &rateLimitWindow{start: now}
+		s.windows[key] = w
+	} else if now.Sub(w.start) >= s.opts.Interval {
+		if w.suppressed > 0 {
+			summaries = append(summaries, summary(now, key, w.suppressed))
+		}
+		*w = rateLimitWindow{start: now}
+	}
+
+	w.count++
+	if w.count <= s.opts.Burst {
+		return true, summaries
+	}
+	w.suppressed++
+	return false, summaries
+}
+
+// expire returns the summaries of the windows whose interval is over and forgets them.
+func (s *rateLimitState) expire() []slog.Record {
+	now := s.opts.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sweepLocked(now, now.Add(-s.opts.Interval))
+}
+
+// sweep returns the summaries of all the windows and forgets them.
+func (s *rateLimitState) sweep() []slog.Record {
+	now := s.opts.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sweepLocked(now, now)
+}
+
+// sweepLocked returns the summaries of the windows started at or before the given time
+// and forgets them. The summaries are sorted by message and level.
+func (s *rateLimitState) sweepLocked(now, startedBefore time.Time) []slog.Record {
+	s.lastSweep = now
+	var expired []samplingKey
+	for key, w := range s.windows {
+		if !w.start.After(startedBefore) {
+			expired = append(expired, key)
+		}
+	}
+	slices.SortFunc(expired, func(a, b samplingKey) int {
+		return cmp.Or(strings.Compare(a.msg, b.msg), cmp.Compare(a.level, b.level))
+	})
+
+	var summaries []slog.Record
+	for _, key := range expired {
+		if n := s.windows[key].suppressed; n > 0 {
+			summaries = append(summaries, summary(now, key, n))
+		}
+		delete(s.windows, key)
+	}
+	return summaries
+}
+
+func (s *rateLimitState) emit(ctx context.Context, summaries []slog.Record) error {
+	var errs []error
+	for _, r := range summaries {
+		if !s.root.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := s.root.Handle(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func summary(now time.Time, key samplingKey, suppressed int) slog.Record {
+	r := slog.NewRecord(now, key.level, fmt.Sprintf("suppressed %d messages", suppressed), 0)
+	r.AddAttrs(slog.String("message", key.msg), slog.Int("suppressed", suppressed))
+	return r
+}
@@ -0,0 +1,108 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	c := qt.New(t)
+
+	clock := &fakeClock{now: testTime}
+	rec := loggertest.New()
+	h := logger.NewRateLimitHandler(rec, logger.RateLimitOptions{
+		Interval: time.Second,
+		Burst:    2,
+		Now:      clock.Now,
+	})
+	defer h.Close(context.Background())
+	l := slog.New(h).With("k", "v")
+
+	for i := 0; i < 5; i++ {
+		l.Warn("storm")
+	}
+	l.Info("other")
+	c.Assert(rec.FindEntries(slog.LevelWarn, "storm"), qt.HasLen, 2)
+	c.Assert(rec.Len(), qt.Equals, 3)
+
+	// the summary is emitted with the first record after the interval
+	rec.Reset()
+	clock.Advance(time.Second)
+	l.Warn("storm")
+	c.Assert(rec.Entries(), qt.HasLen, 2)
+	c.Assert(rec.Entries()[0].Message, qt.Equals, "suppressed 3 messages")
+	c.Assert(rec.Entries()[0].Attrs, qt.DeepEquals, []slog.Attr{slog.String("message", "storm"), slog.Int("suppressed", 3)})
+	c.Assert(rec.Entries()[1].Message, qt.Equals, "storm")
+
+	// summaries of other messages are emitted periodically
+	rec.Reset()
+	l.Warn("storm")
+	l.Warn("storm")
+	clock.Advance(time.Second)
+	l.Info("unrelated")
+	rec.AssertEntry(t, slog.LevelWarn, "suppressed 1 messages", slog.String("message", "storm"))
+	rec.AssertEntry(t, slog.LevelInfo, "unrelated")
+}
+
+func TestRateLimitHandler_Flush(t *testing.T) {
+	c := qt.New(t)
+
+	clock := &fakeClock{now: testTime}
+	rec := loggertest.New()
+	var flushed int
+	h := logger.NewRateLimitHandler(flushHandler{Handler: rec, flushed: &flushed}, logger.RateLimitOptions{Now: clock.Now})
+	defer h.Close(context.Background())
+	l := slog.New(h)
+
+	l.Error("b")
+	l.Error("b")
+	l.Error("a")
+	l.Error("a")
+	l.Error("a")
+	rec.Reset()
+
+	c.Assert(h.Flush(context.Background()), qt.IsNil)
+	c.Assert(flushed, qt.Equals, 1)
+	entries := rec.Entries()
+	c.Assert(entries, qt.HasLen, 2)
+	c.Assert(entries[0].Message, qt.Equals, "suppressed 2 messages")
+	c.Assert(entries[1].Message, qt.Equals, "suppressed 1 messages")
+
+	// nothing is left to report
+	rec.Reset()
+	c.Assert(h.Flush(context.Background()), qt.IsNil)
+	c.Assert(rec.Len(), qt.Equals, 0)
+}
+
+func TestRateLimitHandler_Close(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	h := logger.NewRateLimitHandler(rec, logger.RateLimitOptions{Interval: 10 * time.Millisecond})
+	l := slog.New(h)
+
+	l.Warn("storm")
+	l.Warn("storm")
+	l.Warn("storm")
+
+	// no record is logged after the storm: the summary is emitted by the background goroutine
+	for deadline := time.Now().Add(5 * time.Second); rec.Len() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	rec.AssertEntry(t, slog.LevelWarn, "suppressed 2 messages", slog.String("message", "storm"))
+
+	l.Info("a")
+	l.Info("a")
+	c.Assert(h.Close(context.Background()), qt.IsNil)
+	rec.AssertEntry(t, slog.LevelInfo, "suppressed 1 messages", slog.String("message", "a"))
+	c.Assert(rec.Len(), qt.Equals, 4)
+	c.Assert(h.Close(context.Background()), qt.IsNil)
+	c.Assert(rec.Len(), qt.Equals, 4)
+}
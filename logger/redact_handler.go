@@ -0,0 +1,300 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedactedValue is the value that replaces redacted attributes by default.
+const DefaultRedactedValue = "[REDACTED]"
+
+// Secret is a marker interface for values that must never be logged.
+// Attributes whose values implement it are always redacted by RedactHandler.
+type Secret interface {
+	Secret()
+}
+
+// RedactOptions are options for RedactHandler.
+type RedactOptions struct {
+	// Keys are the patterns of the keys of the attributes to redact, in the syntax of path.Match,
+	// e.g. "password" or "*token*". They are matched case-insensitively against the keys
+	// of the attributes in any group. If a group matches, the whole group is redacted.
+	Keys []string
+
+	// Values are the expressions that match secrets in string values, e.g. card numbers.
+	// The matching parts of the values are replaced.
+	Values []*regexp.Regexp
+
+	// Replacement is the value that replaces redacted attributes and the matching parts of
+	// string values. Defaults to DefaultRedactedValue.
+	Replacement string
+}
+
+var (
+	_ slog.Handler = (*RedactHandler)(nil)
+	_ Flusher      = (*RedactHandler)(nil)
+	_ Hook         = (*RedactHook)(nil)
+)
+
+// RedactHandler is a slog.Handler wrapper that redacts secrets from the attributes of the records
+// and from the attributes added with WithAttrs (and thus with Slog.WithField and the like).
+// An attribute is redacted if its key matches one of RedactOptions.Keys or its value
+// implements Secret; the parts of string values matching RedactOptions.Values are replaced,
+// as are the parts of the messages of error values matching them.
+// LogValuer values are resolved before they are checked, and groups are redacted recursively,
+// except for ErrorValue values, which are kept with the messages of their errors redacted,
+// so that the wrapped handler renders them as usual.
+//
+// The message of the records is not redacted. Hooks are fired before the handler of the logger,
+// so the records passed to them are not redacted either; wrap the hooks in a RedactHook
+// built from the same RedactOptions to redact them.
+type RedactHandler struct {
+	next slog.Handler
+	redactor
+}
+
+// redactor redacts attributes as described by RedactHandler.
+type redactor struct {
+	opts RedactOptions
+}
+
+// NewRedactHandler returns a RedactHandler that passes redacted records to next.
+// It returns an error if one of the key patterns is malformed.
+func NewRedactHandler(next slog.Handler, opts RedactOptions) (*RedactHandler, error) {
+	rd, err := newRedactor(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &RedactHandler{
+		next:     next,
+		redactor: rd,
+	}, nil
+}
+
+func newRedactor(opts RedactOptions) (redactor, error) {
+	keys := make([]string, len(opts.Keys))
+	for i, key := range opts.Keys {
+		key = strings.ToLower(key)
+		if _, err := path.Match(key, ""); err != nil {
+			return redactor{}, fmt.Errorf("logger: redacted key pattern %q: %w", opts.Keys[i], err)
+		}
+		keys[i] = key
+	}
+	opts.Keys = keys
+	if opts.Replacement == "" {
+		opts.Replacement = DefaultRedactedValue
+	}
+	return redactor{opts: opts}, nil
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle passes the record with the redacted attributes to the wrapped handler.
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, h.redactRecord(r))
+}
+
+// WithAttrs returns a new RedactHandler whose wrapped handler has the given attributes redacted.
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i], _ = h.redact(a)
+	}
+	return &RedactHandler{next: h.next.WithAttrs(redacted), redactor: h.redactor}
+}
+
+// WithGroup returns a new RedactHandler whose wrapped handler has the given group.
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+// Flush flushes the wrapped handler if it implements Flusher.
+func (h *RedactHandler) Flush(ctx context.Context) error {
+	return flushHandler(ctx, h.next)
+}
+
+// RedactHook is a Hook wrapper that redacts the records before passing them to the wrapped hook,
+// the same way RedactHandler does, as hooks get the records before the handler of the logger:
+//
+//	opts := logger.RedactOptions{Keys: []string{"*password*"}}
+//	h, err := logger.NewRedactHandler(slog.NewJSONHandler(os.Stderr, nil), opts)
+//	...
+//	hook, err := logger.NewRedactHook(sentryHook, opts)
+//	...
+//	sl := logger.NewSlog(slog.New(h))
+//	sl.AddHook(hook)
+type RedactHook struct {
+	next Hook
+	redactor
+}
+
+// NewRedactHook returns a RedactHook that fires next with redacted records.
+// It returns an error if one of the key patterns is malformed.
+func NewRedactHook(next Hook, opts RedactOptions) (*RedactHook, error) {
+	rd, err := newRedactor(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &RedactHook{
+		next:     next,
+		redactor: rd,
+	}, nil
+}
+
+// Levels returns the levels of the wrapped hook.
+func (h *RedactHook) Levels() []slog.Level {
+	return h.next.Levels()
+}
+
+// Fire fires the wrapped hook with the record with the redacted attributes.
+func (h *RedactHook) Fire(ctx context.Context, r slog.Record) error {
+	return h.next.Fire(ctx, h.redactRecord(r))
+}
+
+// redactRecord returns r with its attributes redacted, or r itself if there is nothing to redact.
+func (rd redactor) redactRecord(r slog.Record) slog.Record {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	changed := false
+	r.Attrs(func(a slog.Attr) bool {
+		ra, ok := rd.redact(a)
+		changed = changed || ok
+		attrs = append(attrs, ra)
+		return true
+	})
+	if !changed {
+		return r
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(attrs...)
+	return nr
+}
+
+// redact returns the redacted attribute and whether it differs from a.
+func (rd redactor) redact(a slog.Attr) (slog.Attr, bool) {
+	if isSecret(a.Value) || rd.matchKey(a.Key) {
+		return slog.String(a.Key, rd.opts.Replacement), true
+	}
+
+	if ev, ok := asErrorValue(a.Value); ok {
+		// keep the ErrorValue, so that the wrapped handler renders it as usual
+		err := rd.redactError(ev.Err)
+		if err == nil {
+			return a, false
+		}
+		return slog.Any(a.Key, &ErrorValue{Err: err, Stack: ev.Stack}), true
+	}
+
+	v := a.Value.Resolve()
+	if isSecret(v) {
+		return slog.String(a.Key, rd.opts.Replacement), true
+	}
+	v, changed := rd.redactValue(v)
+	if !changed && v.Kind() == a.Value.Kind() {
+		return a, false
+	}
+	return slog.Attr{Key: a.Key, Value: v}, true
+}
+
+// redactValue returns the redacted resolved value and whether it differs from v.
+func (rd redactor) redactValue(v slog.Value) (slog.Value, bool) {
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, len(group))
+		changed := false
+		for i, ga := range group {
+			var ok bool
+			attrs[i], ok = rd.redact(ga)
+			changed = changed || ok
+		}
+		if changed {
+			return slog.GroupValue(attrs...), true
+		}
+	case slog.KindString:
+		if s := rd.redactString(v.String()); s != v.String() {
+			return slog.StringValue(s), true
+		}
+	case slog.KindAny:
+		// plain errors, as opposed to the ErrorValue values added by WithError
+		if err, ok := v.Any().(error); ok {
+			if redacted := rd.redactError(err); redacted != nil {
+				return slog.AnyValue(redacted), true
+			}
+		}
+	}
+	return v, false
+}
+
+// redactString replaces the parts of s matching RedactOptions.Values.
+func (rd redactor) redactString(s string) string {
+	for _, re := range rd.opts.Values {
+		s = re.ReplaceAllLiteralString(s, rd.opts.Replacement)
+	}
+	return s
+}
+
+// redactError returns err with the messages of the error and of the errors it wraps redacted,
+// or nil if there is nothing to redact.
+func (rd redactor) redactError(err error) error {
+	msg := rd.redactString(err.Error())
+	changed := msg != err.Error()
+	wrapped := unwrapDirect(err)
+	for i, next := range wrapped {
+		if redacted := rd.redactError(next); redacted != nil {
+			wrapped[i] = redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return &redactedError{msg: msg, err: err, wrapped: wrapped}
+}
+
+// redactedError is an error whose message and wrapped errors are redacted.
+// errors.Is and errors.As still match the original error.
+type redactedError struct {
+	msg     string
+	err     error
+	wrapped []error
+}
+
+func (e *redactedError) Error() string        { return e.msg }
+func (e *redactedError) Unwrap() []error      { return e.wrapped }
+func (e *redactedError) Is(target error) bool { return errors.Is(e.err, target) }
+func (e *redactedError) As(target any) bool   { return errors.As(e.err, target) }
+
+func (rd redactor) matchKey(key string) bool {
+	if len(rd.opts.Keys) == 0 {
+		return false
+	}
+	key = strings.ToLower(key)
+	for _, pattern := range rd.opts.Keys {
+		// the patterns are validated by NewRedactHandler
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isSecret(v slog.Value) bool {
+	if k := v.Kind(); k != slog.KindAny && k != slog.KindLogValuer {
+		return false
+	}
+	_, ok := v.Any().(Secret)
+	return ok
+}
@@ -0,0 +1,207 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+type apiKey string
+
+func (apiKey) Secret() {}
+
+type credentials struct {
+	user     string
+	password string
+}
+
+func (c credentials) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("user", c.user), slog.String("password", c.password))
+}
+
+type secretValuer struct{}
+
+func (secretValuer) Secret() {}
+
+func (secretValuer) LogValue() slog.Value {
+	return slog.StringValue("secret")
+}
+
+func TestRedactHandler(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	h, err := logger.NewRedactHandler(rec, logger.RedactOptions{
+		Keys:   []string{"password", "*token*", "authorization"},
+		Values: []*regexp.Regexp{regexp.MustCompile(`\b\d{4}-\d{4}-\d{4}-\d{4}\b`)},
+	})
+	c.Assert(err, qt.IsNil)
+
+	sl := logger.NewFieldsSlog(slog.New(h)).WithFields(logger.Fields{
+		"Authorization": "Bearer abc",
+		"user":          "bob",
+	})
+	sl.Logger.Info("login",
+		"key", apiKey("k"),
+		"creds", credentials{user: "bob", password: "hunter2"},
+		"valuer", secretValuer{},
+		slog.Group("http", "X-Refresh-Token", "t", "status", 200),
+		"card", "paid with 1234-5678-9012-3456",
+	)
+
+	rec.AssertEntry(t, slog.LevelInfo, "login",
+		slog.String("Authorization", "[REDACTED]"),
+		slog.String("user", "bob"),
+		slog.String("key", "[REDACTED]"),
+		slog.String("creds.user", "bob"),
+		slog.String("creds.password", "[REDACTED]"),
+		slog.String("valuer", "[REDACTED]"),
+		slog.String("http.X-Refresh-Token", "[REDACTED]"),
+		slog.Int("http.status", 200),
+		slog.String("card", "paid with [REDACTED]"),
+	)
+}
+
+func TestRedactHandler_Group(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	h, err := logger.NewRedactHandler(rec, logger.RedactOptions{Keys: []string{"auth"}, Replacement: "***"})
+	c.Assert(err, qt.IsNil)
+
+	l := slog.New(h).WithGroup("req")
+	l.Info("unchanged", "k", "v")
+	l.Info("redacted", slog.Group("auth", "user", "bob"))
+
+	rec.AssertEntry(t, slog.LevelInfo, "unchanged", slog.String("req.k", "v"))
+	rec.AssertEntry(t, slog.LevelInfo, "redacted", slog.String("req.auth", "***"))
+}
+
+func TestNewRedactHandler_BadPattern(t *testing.T) {
+	c := qt.New(t)
+
+	_, err := logger.NewRedactHandler(loggertest.New(), logger.RedactOptions{Keys: []string{"[token"}})
+	c.Assert(err, qt.ErrorMatches, `logger: redacted key pattern "\[token": syntax error in pattern`)
+}
+
+// attrsHandler records the attributes of the handled records as is.
+type attrsHandler struct {
+	attrs *[]slog.Attr
+}
+
+func (attrsHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h attrsHandler) Handle(_ context.Context, r slog.Record) error {
+	r.Attrs(func(a slog.Attr) bool {
+		*h.attrs = append(*h.attrs, a)
+		return true
+	})
+	return nil
+}
+
+func (h attrsHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h attrsHandler) WithGroup(string) slog.Handler { return h }
+
+type queryError struct{ err error }
+
+func (e *queryError) Error() string { return "query: " + e.err.Error() }
+func (e *queryError) Unwrap() error { return e.err }
+
+func TestRedactHandler_Error(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	h, err := logger.NewRedactHandler(logger.NewLogrusTextHandler(&b, &logger.LogrusHandlerOptions{DisableTimestamp: true}), logger.RedactOptions{
+		Values: []*regexp.Regexp{regexp.MustCompile(`hunter\d`)},
+	})
+	c.Assert(err, qt.IsNil)
+
+	sl := logger.NewSlog(slog.New(h))
+	sl.WithError(errors.New("boom")).Error("plain")
+	sl.WithError(&queryError{err: errors.New("bad password hunter2")}).Error("redacted")
+
+	c.Assert(b.String(), qt.Equals, `level=error msg=plain error=boom
+level=error msg=redacted error="query: bad password [REDACTED]"
+`)
+
+	var attrs []slog.Attr
+	h, err = logger.NewRedactHandler(attrsHandler{attrs: &attrs}, logger.RedactOptions{Values: []*regexp.Regexp{regexp.MustCompile(`hunter\d`)}})
+	c.Assert(err, qt.IsNil)
+	sentinel := errors.New("hunter2")
+	slog.New(h).Error("m", "error", &logger.ErrorValue{Err: &queryError{err: sentinel}})
+	c.Assert(attrs, qt.HasLen, 1)
+	ev, ok := attrs[0].Value.Any().(*logger.ErrorValue)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(ev.Error(), qt.Equals, "query: [REDACTED]")
+	c.Assert(errors.Is(ev, sentinel), qt.IsTrue)
+	var qe *queryError
+	c.Assert(errors.As(ev, &qe), qt.IsTrue)
+	c.Assert(ev.LogValue().Group(), qt.DeepEquals, []slog.Attr{
+		slog.String("msg", "query: [REDACTED]"),
+		slog.String("type", "*logger_test.queryError"),
+		slog.Group("chain", slog.Group("0",
+			slog.String("msg", "[REDACTED]"),
+			slog.String("type", "*errors.errorString"),
+		)),
+	})
+}
+
+func TestRedactHandler_PlainError(t *testing.T) {
+	c := qt.New(t)
+
+	var attrs []slog.Attr
+	h, err := logger.NewRedactHandler(attrsHandler{attrs: &attrs}, logger.RedactOptions{Values: []*regexp.Regexp{regexp.MustCompile(`hunter\d`)}})
+	c.Assert(err, qt.IsNil)
+
+	sentinel := errors.New("hunter2")
+	slog.New(h).Error("m", "kept", errors.New("boom"), "redacted", &queryError{err: sentinel})
+	c.Assert(attrs, qt.HasLen, 2)
+	kept, ok := attrs[0].Value.Any().(error)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(kept.Error(), qt.Equals, "boom")
+	redacted, ok := attrs[1].Value.Any().(error)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(redacted.Error(), qt.Equals, "query: [REDACTED]")
+	c.Assert(errors.Is(redacted, sentinel), qt.IsTrue)
+}
+
+func TestRedactHook(t *testing.T) {
+	c := qt.New(t)
+
+	opts := logger.RedactOptions{
+		Keys:   []string{"*password*"},
+		Values: []*regexp.Regexp{regexp.MustCompile(`hunter\d`)},
+	}
+	rec := loggertest.New()
+	h, err := logger.NewRedactHandler(rec, opts)
+	c.Assert(err, qt.IsNil)
+	hook := &testHook{levels: []slog.Level{slog.LevelError}}
+	rh, err := logger.NewRedactHook(hook, opts)
+	c.Assert(err, qt.IsNil)
+	c.Assert(rh.Levels(), qt.DeepEquals, []slog.Level{slog.LevelError})
+
+	sl := logger.NewSlog(slog.New(h))
+	sl.AddHook(rh)
+	sl.WithField("db_password", "x").WithField("user", "bob").WithError(errors.New("login hunter2")).Error("failed")
+
+	c.Assert(hook.records, qt.HasLen, 1)
+	attrs := recordAttrs(hook.records[0])
+	c.Assert(attrs["db_password"], qt.Equals, "[REDACTED]")
+	c.Assert(attrs["user"], qt.Equals, "bob")
+	ev, ok := attrs["error"].(*logger.ErrorValue)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(ev.Error(), qt.Equals, "login [REDACTED]")
+	rec.AssertEntry(t, slog.LevelError, "failed", slog.String("db_password", "[REDACTED]"))
+
+	_, err = logger.NewRedactHook(hook, logger.RedactOptions{Keys: []string{"[token"}})
+	c.Assert(err, qt.ErrorMatches, `logger: redacted key pattern "\[token": syntax error in pattern`)
+}
@@ -0,0 +1,388 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"cmp"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backupTimeFormat is the format of the timestamp in the names of the rotated files.
+// It sorts lexically and contains no characters that are invalid in file names.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotateOptions are options for RotatingFile.
+type RotateOptions struct {
+	// MaxSize is the size in bytes after which the file is rotated. If zero, the file is not
+	// rotated by size. A single write larger than MaxSize still goes to a single file.
+	MaxSize int64
+
+	// RotateEvery is the time after which the file is rotated, counted from when it was opened.
+	// If zero, the file is not rotated by age. Unlike lumberjack's MaxAge, it does not control
+	// how long the rotated files are kept; see MaxBackups for that.
+	RotateEvery time.Duration
+
+	// MaxBackups is the number of rotated files to keep. If zero, all of them are kept.
+	MaxBackups int
+
+	// Compress makes the rotated files compressed with gzip in the background.
+	Compress bool
+
+	// Perm is the permission of the created files. Defaults to 0o644.
+	Perm os.FileMode
+
+	// ReopenOnSIGHUP makes the file reopened when the process receives SIGHUP,
+	// so that it can be rotated by an external tool such as logrotate.
+	ReopenOnSIGHUP bool
+
+	// Now returns the current time. Defaults to time.Now. It is mostly useful in tests.
+	Now func() time.Time
+}
+
+var _ io.WriteCloser = (*RotatingFile)(nil)
+
+// RotatingFile is an io.WriteCloser that writes to a log file and rotates it by size and age,
+// similar to lumberjack. It is meant to be passed to the slog handlers used with NewSlog:
+//
+//	f, err := logger.OpenRotatingFile("/var/log/app.log", logger.RotateOptions{
+//		MaxSize:    100 << 20,
+//		MaxBackups: 5,
+//		Compress:   true,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Close()
+//	sl := logger.NewSlog(slog.New(slog.NewJSONHandler(f, nil)))
+//
+// Rotated files are renamed to <name>-<UTC timestamp><ext>, e.g. app-2023-05-06T07-08-09.000.log,
+// and get a .gz suffix once compressed. RotatingFile is safe for concurrent use.
+type RotatingFile struct {
+	path string
+	opts RotateOptions
+
+	mu sync.Mutex
+	// file is nil after a failed rotation or reopen; the next Write opens it again.
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+
+	// mill serializes compressing and removing the rotated files.
+	mill   sync.Mutex
+	millWG sync.WaitGroup
+
+	signals chan os.Signal
+	stop    chan struct{}
+}
+
+// OpenRotatingFile opens the file at path for appending, creating it and its directory if needed.
+func OpenRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	if opts.Perm == 0 {
+		opts.Perm = 0o644
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	f := &RotatingFile{
+		path: path,
+		opts: opts,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("logger: failed to create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	if opts.ReopenOnSIGHUP {
+		f.signals = make(chan os.Signal, 1)
+		f.stop = make(chan struct{})
+		signal.Notify(f.signals, syscall.SIGHUP)
+		go f.handleSignals()
+	}
+	return f, nil
+}
+
+// Write writes p to the file, rotating it first if the write would exceed MaxSize or the file
+// is older than RotateEvery. If a previous rotation or reopen failed, the file is opened again first.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file regardless of its size and age.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// Reopen closes the file and opens the file at the same path again, e.g. after an external tool
+// has renamed it.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	if err := f.closeFile(); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Close closes the file, stops listening to SIGHUP and waits until the rotated files are compressed.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return os.ErrClosed
+	}
+	f.closed = true
+	err := f.closeFile()
+	if f.signals != nil {
+		signal.Stop(f.signals)
+		close(f.stop)
+	}
+	f.mu.Unlock()
+
+	f.millWG.Wait()
+	return err
+}
+
+func (f *RotatingFile) handleSignals() {
+	for {
+		select {
+		case <-f.signals:
+			if err := f.Reopen(); err != nil && !errors.Is(err, os.ErrClosed) {
+				fmt.Fprintln(os.Stderr, "logger: failed to reopen log file:", err)
+			}
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if f.opts.MaxSize > 0 && f.size > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.RotateEvery > 0 && f.opts.Now().Sub(f.openedAt) >= f.opts.RotateEvery
+}
+
+// closeFile closes the current file, if any. The file is forgotten even if closing it fails,
+// so that it is never written to again. It must be called with f.mu held.
+func (f *RotatingFile) closeFile() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return fmt.Errorf("logger: failed to close log file: %w", err)
+	}
+	return nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, f.opts.Perm)
+	if err != nil {
+		return fmt.Errorf("logger: failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("logger: failed to stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.opts.Now()
+	return nil
+}
+
+// rotate renames the current file, opens a new one and starts the post-processing
+// of the rotated files. If it fails, f.file is left nil and the next Write opens the file again.
+// It must be called with f.mu held.
+func (f *RotatingFile) rotate() error {
+	if err := f.closeFile(); err != nil {
+		return err
+	}
+	backup := f.backupName()
+	if err := os.Rename(f.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("logger: failed to rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.opts.Compress || f.opts.MaxBackups > 0 {
+		f.millWG.Add(1)
+		go func() {
+			defer f.millWG.Done()
+			f.millRun(backup)
+		}()
+	}
+	return nil
+}
+
+// backupName returns an unused name for the rotated file.
+func (f *RotatingFile) backupName() string {
+	dir, prefix, ext := f.nameParts()
+	stamp := f.opts.Now().UTC().Format(backupTimeFormat)
+	name := filepath.Join(dir, prefix+stamp+ext)
+	for i := 1; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = filepath.Join(dir, fmt.Sprintf("%s%s.%d%s", prefix, stamp, i, ext))
+	}
+	return name
+}
+
+// nameParts returns the directory of the file, the prefix of its rotated files and its extension.
+func (f *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir, base := filepath.Split(f.path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+func (f *RotatingFile) millRun(backup string) {
+	f.mill.Lock()
+	defer f.mill.Unlock()
+
+	if f.opts.Compress {
+		if err := compressFile(backup, f.opts.Perm); err != nil {
+			fmt.Fprintln(os.Stderr, "logger: failed to compress rotated log file:", err)
+		}
+	}
+	if f.opts.MaxBackups > 0 {
+		if err := f.removeOldBackups(); err != nil {
+			fmt.Fprintln(os.Stderr, "logger: failed to remove old log files:", err)
+		}
+	}
+}
+
+// removeOldBackups removes the oldest rotated files beyond MaxBackups.
+func (f *RotatingFile) removeOldBackups() error {
+	dir, prefix, ext := f.nameParts()
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type backup struct {
+		name  string
+		stamp string
+		n     int
+	}
+	var backups []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		rest = strings.TrimPrefix(rest, prefix)
+		if len(rest) < len(backupTimeFormat) {
+			continue
+		}
+		b := backup{name: name, stamp: rest[:len(backupTimeFormat)]}
+		if _, err := time.Parse(backupTimeFormat, b.stamp); err != nil {
+			continue
+		}
+		if counter := rest[len(backupTimeFormat):]; counter != "" {
+			n, err := strconv.Atoi(strings.TrimPrefix(counter, "."))
+			if err != nil || !strings.HasPrefix(counter, ".") {
+				continue
+			}
+			b.n = n
+		}
+		backups = append(backups, b)
+	}
+	if len(backups) <= f.opts.MaxBackups {
+		return nil
+	}
+
+	slices.SortFunc(backups, func(a, b backup) int {
+		return cmp.Or(strings.Compare(a.stamp, b.stamp), cmp.Compare(a.n, b.n))
+	})
+	var errs []error
+	for _, b := range backups[:len(backups)-f.opts.MaxBackups] {
+		errs = append(errs, os.Remove(filepath.Join(dir, b.name)))
+	}
+	return errors.Join(errs...)
+}
+
+// compressFile compresses the file at name to name.gz and removes the original.
+func compressFile(name string, perm os.FileMode) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(name + ".gz")
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(name)
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
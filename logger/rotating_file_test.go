@@ -0,0 +1,225 @@
+package logger_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+func readDir(c *qt.C, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	c.Assert(err, qt.IsNil)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func readFile(c *qt.C, name string) string {
+	b, err := os.ReadFile(name)
+	c.Assert(err, qt.IsNil)
+	return string(b)
+}
+
+func TestRotatingFile_Size(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	clock := &fakeClock{now: testTime}
+	f, err := logger.OpenRotatingFile(filepath.Join(dir, "logs", "app.log"), logger.RotateOptions{
+		MaxSize:    10,
+		MaxBackups: 2,
+		Now:        clock.Now,
+	})
+	c.Assert(err, qt.IsNil)
+
+	for i := 0; i < 5; i++ {
+		clock.Advance(time.Second)
+		_, err := fmt.Fprintf(f, "line %d\n", i)
+		c.Assert(err, qt.IsNil)
+	}
+	c.Assert(f.Close(), qt.IsNil)
+	c.Assert(f.Close(), qt.ErrorIs, os.ErrClosed)
+
+	logs := filepath.Join(dir, "logs")
+	c.Assert(readDir(c, logs), qt.DeepEquals, []string{
+		"app-2023-05-06T07-08-13.123.log",
+		"app-2023-05-06T07-08-14.123.log",
+		"app.log",
+	})
+	c.Assert(readFile(c, filepath.Join(logs, "app-2023-05-06T07-08-14.123.log")), qt.Equals, "line 3\n")
+	c.Assert(readFile(c, filepath.Join(logs, "app.log")), qt.Equals, "line 4\n")
+}
+
+func TestRotatingFile_Age(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	c.Assert(os.WriteFile(name, []byte("existing\n"), 0o644), qt.IsNil)
+
+	clock := &fakeClock{now: testTime}
+	f, err := logger.OpenRotatingFile(name, logger.RotateOptions{RotateEvery: time.Hour, Now: clock.Now})
+	c.Assert(err, qt.IsNil)
+	defer f.Close()
+
+	_, _ = io.WriteString(f, "first\n")
+	clock.Advance(time.Hour)
+	_, _ = io.WriteString(f, "second\n")
+	_, _ = io.WriteString(f, "third\n")
+
+	c.Assert(readFile(c, filepath.Join(dir, "app-2023-05-06T08-08-09.123.log")), qt.Equals, "existing\nfirst\n")
+	c.Assert(readFile(c, name), qt.Equals, "second\nthird\n")
+}
+
+func TestRotatingFile_Compress(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	clock := &fakeClock{now: testTime}
+	f, err := logger.OpenRotatingFile(filepath.Join(dir, "app"), logger.RotateOptions{Compress: true, Now: clock.Now})
+	c.Assert(err, qt.IsNil)
+
+	dropTime := func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{ReplaceAttr: dropTime})))
+	sl.Info("rotated")
+	c.Assert(f.Rotate(), qt.IsNil)
+	c.Assert(f.Rotate(), qt.IsNil)
+	sl.Info("current")
+	c.Assert(f.Close(), qt.IsNil)
+
+	c.Assert(readDir(c, dir), qt.DeepEquals, []string{
+		"app",
+		"app-2023-05-06T07-08-09.123.1.gz",
+		"app-2023-05-06T07-08-09.123.gz",
+	})
+	gz, err := os.Open(filepath.Join(dir, "app-2023-05-06T07-08-09.123.gz"))
+	c.Assert(err, qt.IsNil)
+	defer gz.Close()
+	r, err := gzip.NewReader(gz)
+	c.Assert(err, qt.IsNil)
+	b, err := io.ReadAll(r)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "level=INFO msg=rotated\n")
+	c.Assert(readFile(c, filepath.Join(dir, "app")), qt.Equals, "level=INFO msg=current\n")
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	f, err := logger.OpenRotatingFile(name, logger.RotateOptions{ReopenOnSIGHUP: true})
+	c.Assert(err, qt.IsNil)
+
+	_, _ = io.WriteString(f, "before\n")
+	c.Assert(os.Rename(name, name+".1"), qt.IsNil)
+	c.Assert(f.Reopen(), qt.IsNil)
+	_, _ = io.WriteString(f, "after\n")
+	c.Assert(f.Close(), qt.IsNil)
+
+	c.Assert(readFile(c, name+".1"), qt.Equals, "before\n")
+	c.Assert(readFile(c, name), qt.Equals, "after\n")
+
+	_, err = io.WriteString(f, "closed\n")
+	c.Assert(err, qt.ErrorIs, os.ErrClosed)
+	c.Assert(f.Reopen(), qt.ErrorIs, os.ErrClosed)
+}
+
+func TestRotatingFile_RenameFails(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores the permissions of the directory")
+	}
+	c := qt.New(t)
+
+	dir := filepath.Join(t.TempDir(), "logs")
+	name := filepath.Join(dir, "app.log")
+	f, err := logger.OpenRotatingFile(name, logger.RotateOptions{MaxSize: 10})
+	c.Assert(err, qt.IsNil)
+	defer f.Close()
+
+	_, err = io.WriteString(f, "before\n")
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(os.Chmod(dir, 0o555), qt.IsNil)
+	t.Cleanup(func() { _ = os.Chmod(dir, 0o755) })
+	_, err = io.WriteString(f, "failed\n")
+	c.Assert(err, qt.ErrorMatches, "logger: failed to rotate log file: .*")
+
+	c.Assert(os.Chmod(dir, 0o755), qt.IsNil)
+	_, err = io.WriteString(f, "after\n")
+	c.Assert(err, qt.IsNil)
+	c.Assert(f.Close(), qt.IsNil)
+
+	c.Assert(readDir(c, dir), qt.HasLen, 2)
+	c.Assert(readFile(c, name), qt.Equals, "after\n")
+}
+
+func TestRotatingFile_OpenFails(t *testing.T) {
+	c := qt.New(t)
+
+	dir := filepath.Join(t.TempDir(), "logs")
+	name := filepath.Join(dir, "app.log")
+	f, err := logger.OpenRotatingFile(name, logger.RotateOptions{})
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(os.RemoveAll(dir), qt.IsNil)
+	c.Assert(f.Rotate(), qt.ErrorMatches, "logger: failed to open log file: .*")
+	c.Assert(f.Reopen(), qt.ErrorMatches, "logger: failed to open log file: .*")
+	_, err = io.WriteString(f, "lost\n")
+	c.Assert(err, qt.ErrorMatches, "logger: failed to open log file: .*")
+
+	c.Assert(os.MkdirAll(dir, 0o755), qt.IsNil)
+	_, err = io.WriteString(f, "recovered\n")
+	c.Assert(err, qt.IsNil)
+	c.Assert(f.Close(), qt.IsNil)
+	c.Assert(f.Close(), qt.ErrorIs, os.ErrClosed)
+
+	c.Assert(readFile(c, name), qt.Equals, "recovered\n")
+}
+
+func TestRotatingFile_Concurrent(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	f, err := logger.OpenRotatingFile(filepath.Join(dir, "app.log"), logger.RotateOptions{MaxSize: 100})
+	c.Assert(err, qt.IsNil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, _ = fmt.Fprintf(f, "writer %d line %d\n", i, j)
+			}
+		}()
+	}
+	wg.Wait()
+	c.Assert(f.Close(), qt.IsNil)
+
+	var lines int
+	for _, name := range readDir(c, dir) {
+		content := readFile(c, filepath.Join(dir, name))
+		c.Assert(strings.HasSuffix(content, "\n"), qt.IsTrue)
+		lines += strings.Count(content, "\n")
+	}
+	c.Assert(lines, qt.Equals, 500)
+}
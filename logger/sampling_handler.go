@@ -0,0 +1,131 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingOptions are options for SamplingHandler.
+type SamplingOptions struct {
+	// Tick is the sampling interval. Defaults to one second.
+	Tick time.Duration
+
+	// First is the number of records with the same level and message passed through
+	// during each tick.
+	First int
+
+	// Thereafter makes the handler pass through every Thereafter-th record after the first ones
+	// during each tick. If zero, all the records after the first ones are dropped.
+	Thereafter int
+
+	// Now returns the current time. Defaults to time.Now. It is mostly useful in tests.
+	Now func() time.Time
+}
+
+var (
+	_ slog.Handler = (*SamplingHandler)(nil)
+	_ Flusher      = (*SamplingHandler)(nil)
+)
+
+// SamplingHandler is a slog.Handler wrapper that samples records the way zap does:
+// during each tick, it passes through the first SamplingOptions.First records with
+// the same level and message, then every SamplingOptions.Thereafter-th one.
+// It bounds the volume of error storms (e.g. thousands of identical "dropping message"
+// warnings per second) while keeping a representative sample of them.
+//
+// Handlers derived with WithAttrs and WithGroup share the counters of the original handler.
+type SamplingHandler struct {
+	next  slog.Handler
+	state *samplingState
+}
+
+type samplingKey struct {
+	level slog.Level
+	msg   string
+}
+
+type samplingState struct {
+	opts    SamplingOptions
+	mu      sync.Mutex
+	tick    int64
+	counts  map[samplingKey]int
+	dropped atomic.Uint64
+}
+
+// NewSamplingHandler returns a SamplingHandler that passes sampled records to next.
+func NewSamplingHandler(next slog.Handler, opts SamplingOptions) *SamplingHandler {
+	if opts.Tick <= 0 {
+		opts.Tick = time.Second
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &SamplingHandler{
+		next: next,
+		state: &samplingState{
+			opts:   opts,
+			counts: make(map[samplingKey]int),
+		},
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler if it is sampled.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.state.sample(r.Level, r.Message) {
+		h.state.dropped.Add(1)
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a new SamplingHandler whose wrapped handler has the given attributes.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{next: h.next.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup returns a new SamplingHandler whose wrapped handler has the given group.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{next: h.next.WithGroup(name), state: h.state}
+}
+
+// Flush flushes the wrapped handler if it implements Flusher.
+func (h *SamplingHandler) Flush(ctx context.Context) error {
+	return flushHandler(ctx, h.next)
+}
+
+// Dropped returns the number of records dropped so far.
+func (h *SamplingHandler) Dropped() uint64 {
+	return h.state.dropped.Load()
+}
+
+func (s *samplingState) sample(level slog.Level, msg string) bool {
+	tick := s.opts.Now().UnixNano() / int64(s.opts.Tick)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tick != s.tick {
+		clear(s.counts)
+		s.tick = tick
+	}
+	key := samplingKey{level: level, msg: msg}
+	s.counts[key]++
+	n := s.counts[key]
+
+	if n <= s.opts.First {
+		return true
+	}
+	return s.opts.Thereafter > 0 && (n-s.opts.First)%s.opts.Thereafter == 0
+}
@@ -0,0 +1,70 @@
+package logger_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
+)
+
+// fakeClock is a clock for the Now options of the handlers.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestSamplingHandler(t *testing.T) {
+	c := qt.New(t)
+
+	clock := &fakeClock{now: testTime}
+	rec := loggertest.New()
+	h := logger.NewSamplingHandler(rec, logger.SamplingOptions{
+		Tick:       time.Second,
+		First:      2,
+		Thereafter: 3,
+		Now:        clock.Now,
+	})
+	sl := logger.NewSlog(slog.New(h))
+
+	for i := 0; i < 10; i++ {
+		sl.WithField("i", i).Warn("storm")
+	}
+	sl.Error("storm")
+	sl.Warn("other")
+
+	// first 2, then every 3rd: 1, 2, 5, 8
+	c.Assert(rec.FindEntries(slog.LevelWarn, "storm"), qt.HasLen, 4)
+	rec.AssertEntry(t, slog.LevelWarn, "storm", slog.Int("i", 4))
+	rec.AssertEntry(t, slog.LevelWarn, "storm", slog.Int("i", 7))
+	rec.AssertEntry(t, slog.LevelError, "storm")
+	rec.AssertEntry(t, slog.LevelWarn, "other")
+	c.Assert(h.Dropped(), qt.Equals, uint64(6))
+
+	// the counters are reset every tick
+	rec.Reset()
+	clock.Advance(time.Second)
+	sl.Warn("storm")
+	c.Assert(rec.Len(), qt.Equals, 1)
+}
+
+func TestSamplingHandler_NoThereafter(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	l := slog.New(logger.NewSamplingHandler(rec, logger.SamplingOptions{First: 1}))
+	for i := 0; i < 5; i++ {
+		l.Info("storm")
+	}
+	c.Assert(rec.Len(), qt.Equals, 1)
+}
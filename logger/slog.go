@@ -8,22 +8,39 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
+	"runtime"
+	"slices"
+	"time"
 )
 
 const errKey = "error"
 
-const SlogLevelFatal slog.Level = 100
-
-var _ FieldLogger[[]any, *Slog] = (*Slog)(nil)
+var (
+	_ PrimitiveLogger                = (*Slog)(nil)
+	_ BasicLogger                    = (*Slog)(nil)
+	_ LevelLogger                    = (*Slog)(nil)
+	_ TraceLogger                    = (*Slog)(nil)
+	_ FieldLogger[[]any, *Slog]      = (*Slog)(nil)
+	_ TraceFieldLogger[[]any, *Slog] = (*Slog)(nil)
+	_ ErrorLogger                    = (*Slog)(nil)
+)
 
 func NewSlog(logger *slog.Logger) *Slog {
 	return &Slog{
 		Logger: logger,
+		hooks:  &hookSet{},
 	}
 }
 
-// Slog is a wrapper around slog.Logger that implements FieldLogger.
+// std returns a logger wrapping slog.Default() that shares the hooks of the package-level functions.
+func std() *Slog {
+	return &Slog{
+		Logger: slog.Default(),
+		hooks:  &stdHooks,
+	}
+}
+
+// Slog is a wrapper around slog.Logger that implements TraceFieldLogger and ErrorLogger.
 // It is intended to be used as a logrus migration path.
 // It is not intended to be used as a general purpose logger.
 // Note, this is an experimental approach and it's not recommended to be used
@@ -34,12 +51,29 @@ func NewSlog(logger *slog.Logger) *Slog {
 //     (or any other suitable interface from this package)
 //  2. Replace all logrus.New() calls with logger.NewSlog(slog.New())
 //  3. Replace all calls to logrus.* with logger.* (e.g. logrus.WithField -> logger.WithField)
-//  4. You will have to manually adjust remaining incopatibilities (e.g. this package does not
-//     support logrus.Fields and they have to be replaced with logger.SlogFields).
+//  4. Replace logrus.Fields with logger.Fields and use FieldsSlog (see NewFieldsSlog),
+//     or replace them with logger.SlogFields and keep using Slog.
+//  5. You will have to manually adjust remaining incopatibilities.
+//
+// The cmd/logrus2slog command automates most of these steps and reports what is left to do.
 //
 // As this struct is a wrapper around slog.Logger, it is possible to use slog.Logger methods.
+// Use WithContext to pass a context (and thus request-scoped values) to the handler
+// and AddHook to replace logrus hooks. Use Named to build named loggers whose levels
+// can be configured per module at runtime (see LevelRegistry).
+//
+// Fatal and Fatalf flush the handler (see Flusher), run the handlers registered with
+// RegisterExitHandler and terminate the process (see SetExitFunc and WithExitFunc).
+// Panic and Panicf panic with a *PanicValue.
 type Slog struct {
 	*slog.Logger
+
+	ctx      context.Context
+	attrs    []slog.Attr
+	hooks    *hookSet
+	exitFunc func(code int)
+	name     string
+	levels   *LevelRegistry
 }
 
 func (s *Slog) clone() *Slog {
@@ -47,158 +81,241 @@ func (s *Slog) clone() *Slog {
 	return &r
 }
 
+// logContext returns the context passed to the handler with every record.
+func (s *Slog) logContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// WithContext returns a copy of the logger that passes ctx to the underlying
+// handler with every record, so that handlers can extract request-scoped values
+// (trace IDs, request IDs, etc.) from it. It mirrors logrus.Entry.WithContext.
+func (s *Slog) WithContext(ctx context.Context) *Slog {
+	r := s.clone()
+	r.ctx = ctx
+	return r
+}
+
 func (s *Slog) Printf(format string, args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelInfo,
-		fmt.Sprintf(format, args...),
-	)
+	s.sink().emitf(slog.LevelInfo, format, args)
 }
 
 func (s *Slog) Print(args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelInfo,
-		fmt.Sprint(args...),
-	)
+	s.sink().emitp(slog.LevelInfo, args)
 }
 
 func (s *Slog) Fatalf(format string, args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		SlogLevelFatal,
-		fmt.Sprintf(format, args...),
-	)
-	os.Exit(1) //revive:disable-line:deep-exit intentionally not handled
+	s.sink().emitf(SlogLevelFatal, format, args)
+	s.sink().exit(1)
 }
 
 func (s *Slog) Panicf(format string, args ...any) {
-	rec := fmt.Sprintf(format, args...) // TODO: it is possible to catch the record in handler
-	s.Logger.Log(
-		context.Background(),
-		SlogLevelFatal,
-		rec,
-	)
-	panic(rec)
+	rec := fmt.Sprintf(format, args...)
+	s.sink().emit(SlogLevelPanic, rec)
+	panic(s.sink().panicValue(rec))
 }
 
 func (s *Slog) Fatal(args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		SlogLevelFatal,
-		fmt.Sprint(args...),
-	)
-	os.Exit(1) //revive:disable-line:deep-exit intentionally not handled
+	s.sink().emitp(SlogLevelFatal, args)
+	s.sink().exit(1)
 }
 
 func (s *Slog) Panic(args ...any) {
-	rec := fmt.Sprint(args...) // TODO: it is possible to catch the record in handler
-	s.Logger.Log(
-		context.Background(),
-		SlogLevelFatal,
-		rec,
-	)
-	panic(rec)
+	rec := fmt.Sprint(args...)
+	s.sink().emit(SlogLevelPanic, rec)
+	panic(s.sink().panicValue(rec))
+}
+
+func (s *Slog) Tracef(format string, args ...any) {
+	s.sink().emitf(SlogLevelTrace, format, args)
 }
 
 func (s *Slog) Debugf(format string, args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelDebug,
-		fmt.Sprintf(format, args...),
-	)
+	s.sink().emitf(slog.LevelDebug, format, args)
 }
 
 func (s *Slog) Infof(format string, args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelInfo,
-		fmt.Sprintf(format, args...),
-	)
+	s.sink().emitf(slog.LevelInfo, format, args)
 }
 
 func (s *Slog) Warnf(format string, args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelWarn,
-		fmt.Sprintf(format, args...),
-	)
+	s.sink().emitf(slog.LevelWarn, format, args)
 }
 
 func (s *Slog) Warningf(format string, args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelWarn,
-		fmt.Sprintf(format, args...),
-	)
+	s.sink().emitf(slog.LevelWarn, format, args)
 }
 
 func (s *Slog) Errorf(format string, args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelError,
-		fmt.Sprintf(format, args...),
-	)
+	s.sink().emitf(slog.LevelError, format, args)
+}
+
+func (s *Slog) Trace(args ...any) {
+	s.sink().emitp(SlogLevelTrace, args)
 }
 
 func (s *Slog) Debug(args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelDebug,
-		fmt.Sprint(args...),
-	)
+	s.sink().emitp(slog.LevelDebug, args)
 }
 
 func (s *Slog) Info(args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelInfo,
-		fmt.Sprint(args...),
-	)
+	s.sink().emitp(slog.LevelInfo, args)
 }
 
 func (s *Slog) Warn(args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelWarn,
-		fmt.Sprint(args...),
-	)
+	s.sink().emitp(slog.LevelWarn, args)
 }
 
 func (s *Slog) Warning(args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelWarn,
-		fmt.Sprint(args...),
-	)
+	s.sink().emitp(slog.LevelWarn, args)
 }
 
 func (s *Slog) Error(args ...any) {
-	s.Logger.Log(
-		context.Background(),
-		slog.LevelError,
-		fmt.Sprint(args...),
-	)
+	s.sink().emitp(slog.LevelError, args)
 }
 
 func (s *Slog) WithField(key string, value any) *Slog {
-	r := s.clone()
-	r.Logger = r.With(key, value)
-	return r
+	return s.with(key, value)
 }
 
 func (s *Slog) WithFields(fields []any) *Slog {
-	r := s.clone()
-	r.Logger = r.With(fields...)
-	return r
+	return s.with(fields...)
 }
 
+// WithError returns a copy of the logger with err attached as the "error" attribute.
+// The error is rendered as a group by default (see ErrorValue and SetErrorOptions).
 func (s *Slog) WithError(err error) *Slog {
+	return s.withError(err)
+}
+
+// ErrorfErr logs a message at the error level, like Errorf, and returns it as an *AttrError
+// carrying the fields attached to the logger. The format supports the %w verb of fmt.Errorf.
+func (s *Slog) ErrorfErr(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	sk := s.sink()
+	sk.emit(slog.LevelError, err.Error())
+	return sk.attrError("", err, nil)
+}
+
+// WrapErr logs msg at the error level with attrs and err attached (the latter as with WithError),
+// and returns err wrapped in an *AttrError whose message is "msg: err" and which carries
+// the fields attached to the logger and attrs. The attrs are key-value pairs and slog.Attr values,
+// as with slog.Logger.Log. WrapErr logs nothing and returns nil if err is nil.
+func (s *Slog) WrapErr(err error, msg string, attrs ...any) error {
+	if err == nil {
+		return nil
+	}
+	call := argsToAttrs(attrs)
+	sk := s.sink()
+	sk.emitAttrs(slog.LevelError, msg, append(slices.Clip(call), slog.Any(errKey, newErrorValue(err, 1))))
+	return sk.attrError(msg, err, call)
+}
+
+// with returns a copy of the logger with the given fields attached both to the underlying
+// slog.Logger and to the attributes tracked by the wrapper (see PanicValue).
+func (s *Slog) with(args ...any) *Slog {
+	var rec slog.Record
+	rec.Add(args...)
+
 	r := s.clone()
-	r.Logger = r.With(errKey, err)
+	r.Logger = r.With(args...)
+	r.attrs = slices.Clip(r.attrs)
+	rec.Attrs(func(a slog.Attr) bool {
+		r.attrs = append(r.attrs, a)
+		return true
+	})
 	return r
 }
 
+// sink describes where the records of a logger go and how the logger terminates the program.
+type sink struct {
+	ctx      context.Context
+	handler  slog.Handler
+	hooks    *hookSet
+	attrs    []slog.Attr
+	exitFunc func(code int)
+}
+
+func (s *Slog) sink() sink {
+	return sink{
+		ctx:      s.logContext(),
+		handler:  s.Handler(),
+		hooks:    s.hooks,
+		attrs:    s.attrs,
+		exitFunc: s.exitFunc,
+	}
+}
+
+// stdSink returns the sink of the package-level functions.
+func stdSink() sink {
+	return sink{
+		ctx:     context.Background(),
+		handler: slog.Default().Handler(),
+		hooks:   &stdHooks,
+	}
+}
+
+// emitf formats a message according to the format specifier and emits a record,
+// if the handler handles the level. The message is not formatted if the level is disabled.
+func (sk sink) emitf(level slog.Level, format string, args []any) {
+	if !sk.handler.Enabled(sk.ctx, level) {
+		return
+	}
+	sk.handle(level, fmt.Sprintf(format, args...), callerPC())
+}
+
+// emitp formats a message using the default formats for its operands and emits a record,
+// if the handler handles the level. The message is not formatted if the level is disabled.
+func (sk sink) emitp(level slog.Level, args []any) {
+	if !sk.handler.Enabled(sk.ctx, level) {
+		return
+	}
+	sk.handle(level, fmt.Sprint(args...), callerPC())
+}
+
+// emit emits a record with an already formatted message, if the handler handles the level.
+func (sk sink) emit(level slog.Level, msg string) {
+	if !sk.handler.Enabled(sk.ctx, level) {
+		return
+	}
+	sk.handle(level, msg, callerPC())
+}
+
+// emitAttrs emits a record with an already formatted message and attrs, if the handler handles the level.
+func (sk sink) emitAttrs(level slog.Level, msg string, attrs []slog.Attr) {
+	if !sk.handler.Enabled(sk.ctx, level) {
+		return
+	}
+	sk.handle(level, msg, callerPC(), attrs...)
+}
+
+// callerPC returns the program counter of the caller of the exported logging function or method.
+// The emit methods must be called directly from them, so that the source of the record
+// points at their caller rather than at this package.
+func callerPC() uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(4, pcs[:]) // skip [runtime.Callers, callerPC, emit*, the logging function]
+	return pcs[0]
+}
+
+// handle fires the hooks and sends a record with attrs to the handler.
+func (sk sink) handle(level slog.Level, msg string, pc uintptr, attrs ...slog.Attr) {
+	r := slog.NewRecord(time.Now(), level, msg, pc)
+	r.AddAttrs(attrs...)
+	sk.hooks.fire(sk.ctx, r, sk.attrs)
+	_ = sk.handler.Handle(sk.ctx, r)
+}
+
+// panicValue returns the value Panic and Panicf panic with.
+func (sk sink) panicValue(msg string) *PanicValue {
+	return &PanicValue{
+		Message: msg,
+		Attrs:   slices.Clone(sk.attrs),
+	}
+}
+
 // SlogFields is a helper function that converts a list of key-value pairs to a slice of them.
 // Use this function in conjunction with WithFields to pass a slice of key-value pairs to it.
 func SlogFields(args ...any) []any {
@@ -206,147 +323,110 @@ func SlogFields(args ...any) []any {
 }
 
 func Printf(format string, args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelInfo,
-		fmt.Sprintf(format, args...),
-	)
+	stdSink().emitf(slog.LevelInfo, format, args)
 }
 
 func Print(args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelInfo,
-		fmt.Sprint(args...),
-	)
+	stdSink().emitp(slog.LevelInfo, args)
 }
 
 func Fatalf(format string, args ...any) {
-	slog.Log(
-		context.Background(),
-		SlogLevelFatal,
-		fmt.Sprintf(format, args...),
-	)
-	os.Exit(1) //revive:disable-line:deep-exit intentionally not handled
+	stdSink().emitf(SlogLevelFatal, format, args)
+	stdSink().exit(1)
 }
 
 func Panicf(format string, args ...any) {
-	rec := fmt.Sprintf(format, args...) // TODO: it is possible to catch the record in handler
-	slog.Log(
-		context.Background(),
-		SlogLevelFatal,
-		rec,
-	)
-	panic(rec)
+	rec := fmt.Sprintf(format, args...)
+	stdSink().emit(SlogLevelPanic, rec)
+	panic(stdSink().panicValue(rec))
 }
 
 func Fatal(args ...any) {
-	slog.Log(
-		context.Background(),
-		SlogLevelFatal,
-		fmt.Sprint(args...),
-	)
-	os.Exit(1) //revive:disable-line:deep-exit intentionally not handled
+	stdSink().emitp(SlogLevelFatal, args)
+	stdSink().exit(1)
 }
 
 func Panic(args ...any) {
-	rec := fmt.Sprint(args...) // TODO: it is possible to catch the record in handler
-	slog.Log(
-		context.Background(),
-		SlogLevelFatal,
-		rec,
-	)
-	panic(rec)
+	rec := fmt.Sprint(args...)
+	stdSink().emit(SlogLevelPanic, rec)
+	panic(stdSink().panicValue(rec))
+}
+
+func Tracef(format string, args ...any) {
+	stdSink().emitf(SlogLevelTrace, format, args)
 }
 
 func Debugf(format string, args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelDebug,
-		fmt.Sprintf(format, args...),
-	)
+	stdSink().emitf(slog.LevelDebug, format, args)
 }
 
 func Infof(format string, args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelInfo,
-		fmt.Sprintf(format, args...),
-	)
+	stdSink().emitf(slog.LevelInfo, format, args)
 }
 
 func Warnf(format string, args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelWarn,
-		fmt.Sprintf(format, args...),
-	)
+	stdSink().emitf(slog.LevelWarn, format, args)
 }
 
 func Warningf(format string, args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelWarn,
-		fmt.Sprintf(format, args...),
-	)
+	stdSink().emitf(slog.LevelWarn, format, args)
 }
 
 func Errorf(format string, args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelError,
-		fmt.Sprintf(format, args...),
-	)
+	stdSink().emitf(slog.LevelError, format, args)
+}
+
+func Trace(args ...any) {
+	stdSink().emitp(SlogLevelTrace, args)
 }
 
 func Debug(args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelDebug,
-		fmt.Sprint(args...),
-	)
+	stdSink().emitp(slog.LevelDebug, args)
 }
 
 func Info(args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelInfo,
-		fmt.Sprint(args...),
-	)
+	stdSink().emitp(slog.LevelInfo, args)
 }
 
 func Warn(args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelWarn,
-		fmt.Sprint(args...),
-	)
+	stdSink().emitp(slog.LevelWarn, args)
 }
 
 func Warning(args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelWarn,
-		fmt.Sprint(args...),
-	)
+	stdSink().emitp(slog.LevelWarn, args)
 }
 
 func Error(args ...any) {
-	slog.Log(
-		context.Background(),
-		slog.LevelError,
-		fmt.Sprint(args...),
-	)
+	stdSink().emitp(slog.LevelError, args)
+}
+
+func ErrorfErr(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	stdSink().emit(slog.LevelError, err.Error())
+	return stdSink().attrError("", err, nil)
+}
+
+func WrapErr(err error, msg string, attrs ...any) error {
+	if err == nil {
+		return nil
+	}
+	call := argsToAttrs(attrs)
+	stdSink().emitAttrs(slog.LevelError, msg, append(slices.Clip(call), slog.Any(errKey, newErrorValue(err, 1))))
+	return stdSink().attrError(msg, err, call)
 }
 
 func WithField(key string, value any) *Slog {
-	return NewSlog(slog.With(key, value))
+	return std().WithField(key, value)
 }
 
 func WithFields(fields []any) *Slog {
-	return NewSlog(slog.With(fields...))
+	return std().WithFields(fields)
 }
 
 func WithError(err error) *Slog {
-	return NewSlog(slog.With(errKey, err))
+	return std().withError(err)
+}
+
+func WithContext(ctx context.Context) *Slog {
+	return std().WithContext(ctx)
 }
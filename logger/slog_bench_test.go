@@ -0,0 +1,100 @@
+package logger_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+// disabledLoggingFuncs returns all the logging functions and methods that log below the fatal level.
+// Fatal and Panic are excluded as they terminate the program regardless of the level.
+func disabledLoggingFuncs(sl *logger.Slog) []struct {
+	name string
+	fn   func()
+} {
+	return []struct {
+		name string
+		fn   func()
+	}{
+		{"Slog.Printf", func() { sl.Printf("test %s %d", "message", 1) }},
+		{"Slog.Print", func() { sl.Print("test message ", 1) }},
+		{"Slog.Tracef", func() { sl.Tracef("test %s %d", "message", 1) }},
+		{"Slog.Debugf", func() { sl.Debugf("test %s %d", "message", 1) }},
+		{"Slog.Infof", func() { sl.Infof("test %s %d", "message", 1) }},
+		{"Slog.Warnf", func() { sl.Warnf("test %s %d", "message", 1) }},
+		{"Slog.Warningf", func() { sl.Warningf("test %s %d", "message", 1) }},
+		{"Slog.Errorf", func() { sl.Errorf("test %s %d", "message", 1) }},
+		{"Slog.Trace", func() { sl.Trace("test message ", 1) }},
+		{"Slog.Debug", func() { sl.Debug("test message ", 1) }},
+		{"Slog.Info", func() { sl.Info("test message ", 1) }},
+		{"Slog.Warn", func() { sl.Warn("test message ", 1) }},
+		{"Slog.Warning", func() { sl.Warning("test message ", 1) }},
+		{"Slog.Error", func() { sl.Error("test message ", 1) }},
+		{"Printf", func() { logger.Printf("test %s %d", "message", 1) }},
+		{"Print", func() { logger.Print("test message ", 1) }},
+		{"Tracef", func() { logger.Tracef("test %s %d", "message", 1) }},
+		{"Debugf", func() { logger.Debugf("test %s %d", "message", 1) }},
+		{"Infof", func() { logger.Infof("test %s %d", "message", 1) }},
+		{"Warnf", func() { logger.Warnf("test %s %d", "message", 1) }},
+		{"Warningf", func() { logger.Warningf("test %s %d", "message", 1) }},
+		{"Errorf", func() { logger.Errorf("test %s %d", "message", 1) }},
+		{"Trace", func() { logger.Trace("test message ", 1) }},
+		{"Debug", func() { logger.Debug("test message ", 1) }},
+		{"Info", func() { logger.Info("test message ", 1) }},
+		{"Warn", func() { logger.Warn("test message ", 1) }},
+		{"Warning", func() { logger.Warning("test message ", 1) }},
+		{"Error", func() { logger.Error("test message ", 1) }},
+	}
+}
+
+func disabledSlog() *logger.Slog {
+	return logger.NewSlog(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: logger.SlogLevelFatal})))
+}
+
+func TestSlog_DisabledLevelsDoNotAllocate(t *testing.T) {
+	c := qt.New(t)
+
+	sl := disabledSlog()
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(sl.Logger)
+
+	for _, tt := range disabledLoggingFuncs(sl) {
+		c.Check(testing.AllocsPerRun(100, tt.fn), qt.Equals, float64(0), qt.Commentf("%s", tt.name))
+	}
+}
+
+func BenchmarkSlog_Disabled(b *testing.B) {
+	sl := disabledSlog()
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(sl.Logger)
+
+	for _, bb := range disabledLoggingFuncs(sl) {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bb.fn()
+			}
+		})
+	}
+}
+
+func BenchmarkSlog_Enabled(b *testing.B) {
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	b.Run("Infof", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sl.Infof("test %s %d", "message", i)
+		}
+	})
+	b.Run("Info", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sl.Info("test message ", i)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+// sourceHandler records the sources of the handled records.
+type sourceHandler struct {
+	sources *[]*slog.Source
+}
+
+func (sourceHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h sourceHandler) Handle(_ context.Context, r slog.Record) error {
+	fs := runtime.CallersFrames([]uintptr{r.PC})
+	f, _ := fs.Next()
+	*h.sources = append(*h.sources, &slog.Source{Function: f.Function, File: f.File, Line: f.Line})
+	return nil
+}
+
+func (h sourceHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h sourceHandler) WithGroup(string) slog.Handler { return h }
+
+type sourceTest struct {
+	name string
+	fn   func()
+}
+
+// fileLine returns the location of fn, which must be a single-line function literal.
+func fileLine(fn func()) (string, int) {
+	return runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).FileLine(reflect.ValueOf(fn).Pointer())
+}
+
+func assertSources(c *qt.C, sources *[]*slog.Source, tests []sourceTest) {
+	for _, tt := range tests {
+		*sources = nil
+		tt.fn()
+
+		c.Assert(*sources, qt.HasLen, 1, qt.Commentf("%s", tt.name))
+		file, line := fileLine(tt.fn)
+		c.Check(filepath.Base((*sources)[0].File), qt.Equals, "slog_source_test.go", qt.Commentf("%s", tt.name))
+		c.Check((*sources)[0].File, qt.Equals, file, qt.Commentf("%s", tt.name))
+		c.Check((*sources)[0].Line, qt.Equals, line, qt.Commentf("%s", tt.name))
+	}
+}
+
+func TestSlog_Source(t *testing.T) {
+	c := qt.New(t)
+
+	var sources []*slog.Source
+	sl := logger.NewSlog(slog.New(sourceHandler{sources: &sources})).WithExitFunc(func(int) {})
+
+	assertSources(c, &sources, []sourceTest{
+		{"Printf", func() { sl.Printf("%s", "m") }},
+		{"Print", func() { sl.Print("m") }},
+		{"Fatalf", func() { sl.Fatalf("%s", "m") }},
+		{"Fatal", func() { sl.Fatal("m") }},
+		{"Panicf", func() { defer func() { _ = recover() }(); sl.Panicf("%s", "m") }},
+		{"Panic", func() { defer func() { _ = recover() }(); sl.Panic("m") }},
+		{"Tracef", func() { sl.Tracef("%s", "m") }},
+		{"Debugf", func() { sl.Debugf("%s", "m") }},
+		{"Infof", func() { sl.Infof("%s", "m") }},
+		{"Warnf", func() { sl.Warnf("%s", "m") }},
+		{"Warningf", func() { sl.Warningf("%s", "m") }},
+		{"Errorf", func() { sl.Errorf("%s", "m") }},
+		{"Trace", func() { sl.Trace("m") }},
+		{"Debug", func() { sl.Debug("m") }},
+		{"Info", func() { sl.Info("m") }},
+		{"Warn", func() { sl.Warn("m") }},
+		{"Warning", func() { sl.Warning("m") }},
+		{"Error", func() { sl.Error("m") }},
+		{"WithField", func() { sl.WithField("k", "v").Info("m") }},
+		{"WithContext", func() { sl.WithContext(context.Background()).Infof("%s", "m") }},
+		{"ErrorfErr", func() { _ = sl.ErrorfErr("%s", "m") }},
+		{"WrapErr", func() { _ = sl.WrapErr(io.EOF, "m", "k", "v") }},
+		{"interface", func() { logger.LevelLogger(sl).Infof("%s", "m") }},
+	})
+}
+
+func TestFieldsSlog_Source(t *testing.T) {
+	c := qt.New(t)
+
+	var sources []*slog.Source
+	fl := logger.NewFieldsSlog(slog.New(sourceHandler{sources: &sources}))
+
+	assertSources(c, &sources, []sourceTest{
+		{"Infof", func() { fl.Infof("%s", "m") }},
+		{"WithFields", func() { fl.WithFields(logger.Fields{"k": "v"}).Info("m") }},
+		{"WrapErr", func() { _ = fl.WrapErr(io.EOF, "m") }},
+		{"interface", func() { logger.TraceLogger(fl).Tracef("%s", "m") }},
+	})
+}
+
+func TestSource(t *testing.T) {
+	c := qt.New(t)
+
+	var sources []*slog.Source
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(sourceHandler{sources: &sources}))
+	defer logger.SetExitFunc(nil)
+	logger.SetExitFunc(func(int) {})
+
+	assertSources(c, &sources, []sourceTest{
+		{"Printf", func() { logger.Printf("%s", "m") }},
+		{"Print", func() { logger.Print("m") }},
+		{"Fatalf", func() { logger.Fatalf("%s", "m") }},
+		{"Fatal", func() { logger.Fatal("m") }},
+		{"Panicf", func() { defer func() { _ = recover() }(); logger.Panicf("%s", "m") }},
+		{"Panic", func() { defer func() { _ = recover() }(); logger.Panic("m") }},
+		{"Tracef", func() { logger.Tracef("%s", "m") }},
+		{"Debugf", func() { logger.Debugf("%s", "m") }},
+		{"Infof", func() { logger.Infof("%s", "m") }},
+		{"Warnf", func() { logger.Warnf("%s", "m") }},
+		{"Warningf", func() { logger.Warningf("%s", "m") }},
+		{"Errorf", func() { logger.Errorf("%s", "m") }},
+		{"Trace", func() { logger.Trace("m") }},
+		{"Debug", func() { logger.Debug("m") }},
+		{"Info", func() { logger.Info("m") }},
+		{"Warn", func() { logger.Warn("m") }},
+		{"Warning", func() { logger.Warning("m") }},
+		{"Error", func() { logger.Error("m") }},
+		{"WithField", func() { logger.WithField("k", "v").Info("m") }},
+		{"ErrorfErr", func() { _ = logger.ErrorfErr("%s", "m") }},
+		{"WrapErr", func() { _ = logger.WrapErr(io.EOF, "m") }},
+	})
+}
+
+func TestSlog_AddSource(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(slog.NewJSONHandler(&b, &slog.HandlerOptions{AddSource: true})))
+
+	_, file, line, _ := runtime.Caller(0)
+	sl.Infof("test %s", "message")
+
+	var rec struct {
+		Source slog.Source `json:"source"`
+	}
+	c.Assert(json.Unmarshal(b.Bytes(), &rec), qt.IsNil)
+	c.Assert(rec.Source.File, qt.Equals, file)
+	c.Assert(rec.Source.Line, qt.Equals, line+1)
+	c.Assert(rec.Source.Function, qt.Equals, "github.com/go-extras/go-kit/logger_test.TestSlog_AddSource")
+}
@@ -8,70 +8,69 @@ import (
 	qt "github.com/frankban/quicktest"
 
 	"github.com/go-extras/go-kit/logger"
+	"github.com/go-extras/go-kit/logger/loggertest"
 )
 
 func TestPrintf(t *testing.T) {
 	c := qt.New(t)
 
-	var b bytes.Buffer
-	h := slog.NewTextHandler(&b, &slog.HandlerOptions{
-		ReplaceAttr: func(_groups []string, a slog.Attr) slog.Attr {
-			if a.Key == "time" {
-				return slog.Attr{
-					Key:   "time",
-					Value: slog.StringValue("now"),
-				}
-			}
-			return a
-		},
-	})
-
-	sl := logger.NewSlog(slog.New(h))
+	rec := loggertest.New()
+	sl := logger.NewSlog(slog.New(rec))
 	sl.Printf("test %s", "message")
 
-	c.Assert(b.String(), qt.Equals, "time=now level=INFO msg=\"test message\"\n")
+	entries := rec.Entries()
+	c.Assert(entries, qt.HasLen, 1)
+	c.Assert(entries[0].Level, qt.Equals, slog.LevelInfo)
+	c.Assert(entries[0].Message, qt.Equals, "test message")
+	c.Assert(entries[0].Attrs, qt.HasLen, 0)
 }
 
 func TestWithFields(t *testing.T) {
 	c := qt.New(t)
 
-	var b bytes.Buffer
-	h := slog.NewTextHandler(&b, &slog.HandlerOptions{
-		ReplaceAttr: func(_groups []string, a slog.Attr) slog.Attr {
-			if a.Key == "time" {
-				return slog.Attr{
-					Key:   "time",
-					Value: slog.StringValue("now"),
-				}
-			}
-			return a
-		},
-	})
-
-	sl := logger.NewSlog(slog.New(h))
+	rec := loggertest.New()
+	sl := logger.NewSlog(slog.New(rec))
 	sl.WithFields(logger.SlogFields("mykey", "test key")).Print("test message")
 
-	c.Assert(b.String(), qt.Equals, "time=now level=INFO msg=\"test message\" mykey=\"test key\"\n")
+	entries := rec.Entries()
+	c.Assert(entries, qt.HasLen, 1)
+	c.Assert(entries[0].Level, qt.Equals, slog.LevelInfo)
+	c.Assert(entries[0].Message, qt.Equals, "test message")
+	c.Assert(entries[0].Attrs, qt.DeepEquals, []slog.Attr{slog.String("mykey", "test key")})
 }
 
 func TestWithField(t *testing.T) {
 	c := qt.New(t)
 
-	var b bytes.Buffer
-	h := slog.NewTextHandler(&b, &slog.HandlerOptions{
-		ReplaceAttr: func(_groups []string, a slog.Attr) slog.Attr {
-			if a.Key == "time" {
-				return slog.Attr{
-					Key:   "time",
-					Value: slog.StringValue("now"),
-				}
-			}
-			return a
-		},
-	})
-
-	sl := logger.NewSlog(slog.New(h))
+	rec := loggertest.New()
+	sl := logger.NewSlog(slog.New(rec))
 	sl.WithField("mykey", "test key").Print("test message")
 
-	c.Assert(b.String(), qt.Equals, "time=now level=INFO msg=\"test message\" mykey=\"test key\"\n")
+	entries := rec.Entries()
+	c.Assert(entries, qt.HasLen, 1)
+	c.Assert(entries[0].Level, qt.Equals, slog.LevelInfo)
+	c.Assert(entries[0].Message, qt.Equals, "test message")
+	c.Assert(entries[0].Attrs, qt.DeepEquals, []slog.Attr{slog.String("mykey", "test key")})
+}
+
+func TestTrace(t *testing.T) {
+	rec := loggertest.New()
+
+	var tl logger.TraceFieldLogger[[]any, *logger.Slog] = logger.NewSlog(slog.New(rec))
+	tl.Tracef("test %s", "message")
+	tl.WithField("mykey", "test key").Trace("test message")
+
+	rec.AssertEntry(t, logger.SlogLevelTrace, "test message")
+	rec.AssertEntry(t, logger.SlogLevelTrace, "test message", slog.String("mykey", "test key"))
+}
+
+func TestTrace_Disabled(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	sl := logger.NewSlog(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	sl.Trace("test message")
+	sl.Tracef("test %s", "message")
+
+	c.Assert(b.String(), qt.Equals, "")
 }
@@ -0,0 +1,197 @@
+// Package logger.
+//
+// License: MIT
+// Copyright: 2023, Denis Voytyuk
+package logger
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+var (
+	_ PrimitiveLogger                           = (*UpgradedLogger)(nil)
+	_ BasicLogger                               = (*UpgradedLogger)(nil)
+	_ LevelLogger                               = (*UpgradedLogger)(nil)
+	_ TraceLogger                               = (*UpgradedLogger)(nil)
+	_ FieldLogger[Fields, *UpgradedLogger]      = (*UpgradedLogger)(nil)
+	_ TraceFieldLogger[Fields, *UpgradedLogger] = (*UpgradedLogger)(nil)
+	_ ErrorLogger                               = (*UpgradedLogger)(nil)
+)
+
+// callDepth is the number of stack frames between the output function of UpgradedLogger
+// and the caller of its logging methods.
+const callDepth = 3
+
+// UpgradedLogger implements all the interfaces of this package on top of a PrimitiveLogger
+// or a standard library *log.Logger. Every message is prefixed with its level (e.g. "[INFO] ")
+// and followed by the fields of the logger as key=value pairs:
+//
+//	[WARN] connection lost attempt=3 host=example.com
+//
+// Fatal and Fatalf call Exit after writing the message, and Panic and Panicf panic with a *PanicValue.
+type UpgradedLogger struct {
+	output func(calldepth int, s string)
+	attrs  []slog.Attr
+}
+
+// Upgrade returns an UpgradedLogger writing to l with its Print method, so that any logger
+// accepted by e.g. pubsub.WithLogger can be passed to APIs requiring a LevelLogger or a FieldLogger.
+// If l is a *log.Logger, Upgrade is the same as NewStdLogger.
+func Upgrade(l PrimitiveLogger) *UpgradedLogger {
+	if std, ok := l.(*log.Logger); ok {
+		return NewStdLogger(std)
+	}
+	return &UpgradedLogger{
+		output: func(_ int, s string) {
+			l.Print(s)
+		},
+	}
+}
+
+// NewStdLogger returns an UpgradedLogger writing to the standard library logger l.
+// Unlike Upgrade, it reports the correct caller when l has the log.Lshortfile or log.Llongfile flag.
+func NewStdLogger(l *log.Logger) *UpgradedLogger {
+	return &UpgradedLogger{
+		output: func(calldepth int, s string) {
+			_ = l.Output(calldepth+1, s)
+		},
+	}
+}
+
+func (u *UpgradedLogger) Printf(format string, args ...any) {
+	u.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (u *UpgradedLogger) Print(args ...any) {
+	u.log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+func (u *UpgradedLogger) Fatalf(format string, args ...any) {
+	u.log(SlogLevelFatal, fmt.Sprintf(format, args...))
+	Exit(1)
+}
+
+func (u *UpgradedLogger) Panicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	u.log(SlogLevelPanic, msg)
+	panic(&PanicValue{Message: msg, Attrs: slices.Clone(u.attrs)})
+}
+
+func (u *UpgradedLogger) Fatal(args ...any) {
+	u.log(SlogLevelFatal, fmt.Sprint(args...))
+	Exit(1)
+}
+
+func (u *UpgradedLogger) Panic(args ...any) {
+	msg := fmt.Sprint(args...)
+	u.log(SlogLevelPanic, msg)
+	panic(&PanicValue{Message: msg, Attrs: slices.Clone(u.attrs)})
+}
+
+func (u *UpgradedLogger) Tracef(format string, args ...any) {
+	u.log(SlogLevelTrace, fmt.Sprintf(format, args...))
+}
+
+func (u *UpgradedLogger) Debugf(format string, args ...any) {
+	u.log(slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (u *UpgradedLogger) Infof(format string, args ...any) {
+	u.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (u *UpgradedLogger) Warnf(format string, args ...any) {
+	u.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (u *UpgradedLogger) Warningf(format string, args ...any) {
+	u.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (u *UpgradedLogger) Errorf(format string, args ...any) {
+	u.log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+func (u *UpgradedLogger) Trace(args ...any) {
+	u.log(SlogLevelTrace, fmt.Sprint(args...))
+}
+
+func (u *UpgradedLogger) Debug(args ...any) {
+	u.log(slog.LevelDebug, fmt.Sprint(args...))
+}
+
+func (u *UpgradedLogger) Info(args ...any) {
+	u.log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+func (u *UpgradedLogger) Warn(args ...any) {
+	u.log(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (u *UpgradedLogger) Warning(args ...any) {
+	u.log(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+func (u *UpgradedLogger) Error(args ...any) {
+	u.log(slog.LevelError, fmt.Sprint(args...))
+}
+
+func (u *UpgradedLogger) ErrorfErr(format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	u.log(slog.LevelError, err.Error())
+	return &AttrError{Err: err, Attrs: slices.Clone(u.attrs)}
+}
+
+func (u *UpgradedLogger) WrapErr(err error, msg string, attrs ...any) error {
+	if err == nil {
+		return nil
+	}
+	call := argsToAttrs(attrs)
+	u.log(slog.LevelError, msg, append(slices.Clip(call), slog.Any(errKey, err))...)
+	return &AttrError{Message: msg, Err: err, Attrs: slices.Concat(u.attrs, call)}
+}
+
+func (u *UpgradedLogger) WithField(key string, value any) *UpgradedLogger {
+	return u.with(key, value)
+}
+
+func (u *UpgradedLogger) WithFields(fields Fields) *UpgradedLogger {
+	return u.with(fields.Args()...)
+}
+
+func (u *UpgradedLogger) WithError(err error) *UpgradedLogger {
+	return u.with(errKey, err)
+}
+
+func (u *UpgradedLogger) with(args ...any) *UpgradedLogger {
+	var rec slog.Record
+	rec.Add(args...)
+
+	r := *u
+	r.attrs = slices.Clip(r.attrs)
+	rec.Attrs(func(a slog.Attr) bool {
+		r.attrs = append(r.attrs, a)
+		return true
+	})
+	return &r
+}
+
+// log writes the level, the message, the fields and attrs. It must be called directly from
+// the logging methods, so that the standard library logger reports the right caller.
+func (u *UpgradedLogger) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	var b strings.Builder
+	b.WriteByte('[')
+	b.WriteString(Level(level).String())
+	b.WriteString("] ")
+	b.WriteString(msg)
+	for _, a := range slices.Concat(u.attrs, attrs) {
+		for _, f := range appendFlatField(nil, "", a) {
+			appendKeyValueText(&b, f)
+		}
+	}
+	u.output(callDepth, b.String())
+}
@@ -0,0 +1,96 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/go-extras/go-kit/logger"
+)
+
+// printLogger is a PrimitiveLogger that writes to a buffer.
+type printLogger struct {
+	b *bytes.Buffer
+}
+
+func (l printLogger) Printf(format string, args ...any) {
+	fmt.Fprintf(l.b, format+"\n", args...)
+}
+
+func (l printLogger) Print(args ...any) {
+	fmt.Fprintln(l.b, args...)
+}
+
+func TestUpgrade(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	var ll logger.LevelLogger = logger.Upgrade(printLogger{b: &b})
+	ll.Infof("test %s", "message")
+	ll.Warning("warning")
+	ll.Print("print")
+
+	fl := logger.Upgrade(printLogger{b: &b})
+	fl.WithFields(logger.Fields{"b": "with space", "a": 1}).WithError(errors.New("boom")).Trace("trace")
+	fl.WithField("k", "v").Errorf("error")
+
+	c.Assert(b.String(), qt.Equals, `[INFO] test message
+[WARN] warning
+[INFO] print
+[TRACE] trace a=1 b="with space" error=boom
+[ERROR] error k=v
+`)
+}
+
+func TestUpgrade_FatalPanic(t *testing.T) {
+	c := qt.New(t)
+
+	var codes []int
+	logger.SetExitFunc(func(code int) { codes = append(codes, code) })
+	defer logger.SetExitFunc(nil)
+
+	var b bytes.Buffer
+	fl := logger.Upgrade(printLogger{b: &b}).WithField("k", "v")
+	fl.Fatalf("fatal %d", 1)
+
+	c.Assert(codes, qt.DeepEquals, []int{1})
+
+	defer func() {
+		pv, ok := recover().(*logger.PanicValue)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(pv.Message, qt.Equals, "panic")
+		c.Assert(pv.Attrs, qt.DeepEquals, []slog.Attr{slog.String("k", "v")})
+		c.Assert(b.String(), qt.Equals, "[FATAL] fatal 1 k=v\n[PANIC] panic k=v\n")
+	}()
+	fl.Panic("panic")
+}
+
+func TestUpgrade_ErrorLogger(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	fl := logger.Upgrade(printLogger{b: &b}).WithField("k", "v")
+	err := fl.WrapErr(fl.ErrorfErr("query %d", 1), "failed", "n", 2)
+
+	c.Assert(err, qt.ErrorMatches, "failed: query 1")
+	c.Assert(logger.AttrsFromError(err), qt.DeepEquals, []slog.Attr{slog.String("k", "v"), slog.Int("n", 2)})
+	c.Assert(b.String(), qt.Equals, "[ERROR] query 1 k=v\n[ERROR] failed k=v n=2 error=\"query 1\"\n")
+}
+
+func TestNewStdLogger(t *testing.T) {
+	c := qt.New(t)
+
+	var b bytes.Buffer
+	l := logger.Upgrade(log.New(&b, "", log.Lshortfile))
+
+	_, _, line, _ := runtime.Caller(0)
+	l.WithField("k", 1).Infof("test %s", "message")
+
+	c.Assert(b.String(), qt.Equals, fmt.Sprintf("upgrade_test.go:%d: [INFO] test message k=1\n", line+1))
+}
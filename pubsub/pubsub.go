@@ -109,13 +109,25 @@ func (p *Publisher[T]) Unsubscribe(ch Subscriber[T]) {
 // If a subscriber's channel buffer is full, the message will be dropped
 // and a warning will be logged.
 func (p *Publisher[T]) Publish(msg T) {
+	for range p.TryPublish(msg) {
+		p.logger.Print("dropping message because subscriber is too slow (message buffer is full)\n")
+	}
+}
+
+// TryPublish broadcasts a message to all current subscribers, like Publish,
+// but does not log anything: it returns the number of subscribers the message
+// was dropped for because their channel buffer was full.
+// Use it when publishing from the code the logger of the Publisher writes to,
+// such as a slog.Handler, so that a slow subscriber cannot cause a logging loop.
+func (p *Publisher[T]) TryPublish(msg T) (dropped int) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 	for ch := range p.subscribers {
 		select {
 		case ch <- msg:
 		default:
-			p.logger.Print("dropping message because subscriber is too slow (message buffer is full)\n")
+			dropped++
 		}
 	}
+	return dropped
 }
@@ -1,26 +1,22 @@
 package pubsub_test
 
 import (
-	"bytes"
-	"log"
+	"log/slog"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
 
+	"github.com/go-extras/go-kit/logger/loggertest"
 	"github.com/go-extras/go-kit/pubsub"
 )
 
 func TestPublisher(t *testing.T) {
 	c := qt.New(t)
 
-	// instantiate logrus debug logger
-	// Create a buffer to capture the logs
-	var buf bytes.Buffer
+	// Create a logger that records the logs
+	rec := loggertest.New()
 
-	// Create a new logger that writes to the buffer
-	debugLog := log.New(&buf, "[DEBUG] ", log.Ldate|log.Ltime|log.Lshortfile)
-
-	p := pubsub.NewPublisher[string](5, pubsub.WithLogger[string](debugLog))
+	p := pubsub.NewPublisher[string](5, pubsub.WithLogger[string](rec))
 
 	// Subscribe to the publisher
 	sub1 := p.Subscribe()
@@ -72,21 +68,16 @@ func TestPublisher(t *testing.T) {
 		// ok
 	}
 
-	expected := "dropping message because subscriber is too slow (message buffer is full)\n"
-	c.Assert(buf.String(), qt.Not(qt.Contains), expected)
+	c.Assert(rec.HasEntry(slog.LevelInfo, "dropping message because subscriber is too slow (message buffer is full)"), qt.IsFalse)
 }
 
 func TestPublisher_NoReceive(t *testing.T) {
 	c := qt.New(t)
 
-	// instantiate logrus debug logger
-	// Create a buffer to capture the logs
-	var buf bytes.Buffer
-
-	// Create a new logger that writes to the buffer
-	debugLog := log.New(&buf, "[DEBUG] ", log.Ldate|log.Ltime|log.Lshortfile)
+	// Create a logger that records the logs
+	rec := loggertest.New()
 
-	p := pubsub.NewPublisher[string](2, pubsub.WithLogger[string](debugLog))
+	p := pubsub.NewPublisher[string](2, pubsub.WithLogger[string](rec))
 
 	// Subscribe to the publisher
 	// Never receive messages from the subscription
@@ -99,9 +90,28 @@ func TestPublisher_NoReceive(t *testing.T) {
 	// Publish a message above the buffer size
 	p.Publish("message 3")
 
-	expected := "dropping message because subscriber is too slow (message buffer is full)\n"
-	c.Assert(buf.String(), qt.Contains, expected)
+	c.Assert(rec.HasEntry(slog.LevelInfo, "dropping message because subscriber is too slow (message buffer is full)"), qt.IsTrue)
 
 	// Unsubscribe from the publisher
 	p.Unsubscribe(sub1)
 }
+
+func TestPublisher_TryPublish(t *testing.T) {
+	c := qt.New(t)
+
+	rec := loggertest.New()
+	p := pubsub.NewPublisher[string](1, pubsub.WithLogger[string](rec))
+	sub1 := p.Subscribe()
+	sub2 := p.Subscribe()
+
+	c.Assert(p.TryPublish("message 1"), qt.Equals, 0)
+	<-sub1
+	c.Assert(p.TryPublish("message 2"), qt.Equals, 1)
+	c.Assert(p.TryPublish("message 3"), qt.Equals, 2)
+	c.Assert(<-sub1, qt.Equals, "message 2")
+	c.Assert(<-sub2, qt.Equals, "message 1")
+	c.Assert(rec.Len(), qt.Equals, 0)
+
+	p.Unsubscribe(sub1)
+	p.Unsubscribe(sub2)
+}